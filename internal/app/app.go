@@ -6,9 +6,9 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
-	"syscall"
 	"subscription-budget/internal/models"
 	"subscription-budget/internal/services"
+	"syscall"
 	"time"
 
 	"subscription-budget/internal/config"
@@ -17,23 +17,31 @@ import (
 )
 
 type App struct {
-	cfg      *config.Config
-	server   *http.Server
-	services *Services
-	storages *Storages
+	cfg         *config.Config
+	server      *http.Server
+	services    *Services
+	storages    *Storages
+	stopWorkers context.CancelFunc
 }
 
 type Services struct {
 	TeamManag        services.TeamManager
 	UserManag        services.UserManager
 	PullRequestManag services.PullRequestManager
+	OwnershipManag   services.OwnershipManager
+	RepositoryManag  services.RepositoryManager
 	Stat             *services.StatService
+	Reconciler       *services.ReviewerReconciler
+	ReviewSLA        *services.ReviewSLAWorker
+	StalePRs         *services.StalePRWorker
 }
 
 type Storages struct {
-	PullReq storage.PullReqStorage
-	Team    storage.TeamStorage
-	User    storage.UserStorage
+	PullReq   storage.PullReqStorage
+	Team      storage.TeamStorage
+	User      storage.UserStorage
+	Ownership storage.OwnershipStorage
+	Repo      storage.RepositoryStorage
 }
 
 func NewApp(cfg *config.Config) *App {
@@ -65,21 +73,66 @@ func (a *App) initStorages() {
 	}
 
 	a.storages = &Storages{
-		PullReq: storage.NewPullRequestPostgresStorage(poolPG),
-		Team:    storage.NewTeamPostgresStorage(poolPG),
-		User:    storage.NewUserPostgresStorage(poolPG),
+		PullReq:   storage.NewPullRequestPostgresStorage(poolPG),
+		Team:      storage.NewTeamPostgresStorage(poolPG),
+		User:      storage.NewUserPostgresStorage(poolPG),
+		Ownership: storage.NewOwnershipPostgresStorage(poolPG),
+		Repo:      storage.NewRepositoryPostgresStorage(poolPG),
 	}
 }
 
 func (a *App) initServices() {
+	strategies := services.NewStrategyRegistry()
+
+	// time.NewTicker паникует на неположительном интервале
+	if a.cfg.ReviewerReconcileInterval <= 0 {
+		slog.Error("Invalid reviewer reconcile interval", "interval", a.cfg.ReviewerReconcileInterval)
+		os.Exit(1)
+	}
+
+	pullRequestService := services.NewPullRequestService(
+		a.storages.PullReq,
+		a.storages.User,
+		a.storages.Team,
+		a.storages.Ownership,
+		a.storages.Repo,
+		strategies)
+	reconciler := services.NewReviewerReconciler(pullRequestService, a.cfg.ReviewerReconcileInterval)
+	stat := services.NewStatService()
+
+	location, err := time.LoadLocation(a.cfg.WorkTimezone)
+	if err != nil {
+		slog.Error("Failed to load work timezone", "timezone", a.cfg.WorkTimezone, "error", err)
+		os.Exit(1)
+	}
+	if a.cfg.WorkDayStartHour < 0 || a.cfg.WorkDayStartHour >= a.cfg.WorkDayEndHour || a.cfg.WorkDayEndHour > 24 {
+		slog.Error("Invalid working hours", "start", a.cfg.WorkDayStartHour, "end", a.cfg.WorkDayEndHour)
+		os.Exit(1)
+	}
+	if a.cfg.ReviewSLACheckInterval <= 0 {
+		slog.Error("Invalid review SLA check interval", "interval", a.cfg.ReviewSLACheckInterval)
+		os.Exit(1)
+	}
+	if a.cfg.StalePRCheckInterval <= 0 {
+		slog.Error("Invalid stale PR check interval", "interval", a.cfg.StalePRCheckInterval)
+		os.Exit(1)
+	}
+	workingHours := services.WorkingHours{
+		Start:    a.cfg.WorkDayStartHour,
+		End:      a.cfg.WorkDayEndHour,
+		Location: location,
+	}
+
 	a.services = &Services{
-		TeamManag: services.NewTeamService(a.storages.Team),
-		UserManag: services.NewUserService(a.storages.User),
-		PullRequestManag: services.NewPullRequestService(
-			a.storages.PullReq,
-			a.storages.User,
-			a.storages.Team),
-		Stat: services.NewStatService(),
+		TeamManag:        services.NewTeamService(a.storages.Team, strategies, pullRequestService, reconciler),
+		UserManag:        services.NewUserService(a.storages.User, pullRequestService, reconciler),
+		PullRequestManag: pullRequestService,
+		OwnershipManag:   services.NewOwnershipService(a.storages.Ownership, a.storages.Team),
+		RepositoryManag:  services.NewRepositoryService(a.storages.Repo, a.storages.Team, strategies),
+		Stat:             stat,
+		Reconciler:       reconciler,
+		ReviewSLA:        services.NewReviewSLAWorker(pullRequestService, workingHours, a.cfg.ReviewSLACheckInterval, stat),
+		StalePRs:         services.NewStalePRWorker(pullRequestService, a.cfg.StalePRCheckInterval),
 	}
 }
 
@@ -88,6 +141,8 @@ func (a *App) initHTTP() {
 		a.services.TeamManag,
 		a.services.UserManag,
 		a.services.PullRequestManag,
+		a.services.OwnershipManag,
+		a.services.RepositoryManag,
 		a.services.Stat,
 	)
 	if err != nil {
@@ -110,15 +165,51 @@ func (a *App) setupRoutes(handler *handlers.Handler) http.Handler {
 	mux := http.NewServeMux()
 
 	apiRoutes := map[string]http.HandlerFunc{
-		"/team/add": handler.AddTeam,
-		"/team/get": handler.GetTeam,
+		"/team/add":          handler.AddTeam,
+		"/team/get":          handler.GetTeam,
+		"/team/update":       handler.UpdateTeam,
+		"/team/getSettings":  handler.GetTeamSettings,
+		"/team/setSettings":  handler.SetTeamSettings,
+		"/team/stalePRs":     handler.GetStalePRs,
+		"/team/addMember":    handler.AddMember,
+		"/team/removeMember": handler.RemoveMember,
+		"/team/moveMember":   handler.MoveMember,
+		"/team/setPrimary":   handler.SetPrimaryTeam,
+		"/team/archive":      handler.ArchiveTeam,
+		"/team/delete":       handler.DeleteTeam,
+
+		"/users/setIsActive":   handler.SetIsActive,
+		"/users/getReview":     handler.GetUserReviews,
+		"/users/setSkills":     handler.SetSkills,
+		"/users/addAbsence":    handler.AddAbsence,
+		"/users/getAbsences":   handler.GetAbsences,
+		"/users/deleteAbsence": handler.DeleteAbsence,
+
+		"/pullRequest/create":          handler.CreatePR,
+		"/pullRequest/preview":         handler.PreviewPR,
+		"/pullRequest/get":             handler.GetPR,
+		"/pullRequest/update":          handler.UpdatePR,
+		"/pullRequest/merge":           handler.MergePR,
+		"/pullRequest/setDependencies": handler.SetDependencies,
+		"/pullRequest/stack":           handler.GetPRStack,
+		"/pullRequest/ready":           handler.ReadyForReview,
+		"/pullRequest/close":           handler.ClosePR,
+		"/pullRequest/reopen":          handler.ReopenPR,
+		"/pullRequest/review":          handler.SubmitReview,
+		"/pullRequest/rerequestReview": handler.RerequestReview,
+		"/pullRequest/reassign":        handler.ReassignReviewer,
+		"/pullRequest/history":         handler.GetPRHistory,
+		"/pullRequest/comment":         handler.AddComment,
+		"/pullRequest/resolveThread":   handler.ResolveThread,
+		"/pullRequest/unresolveThread": handler.UnresolveThread,
+		"/pullRequest/threads":         handler.GetCommentThreads,
 
-		"/users/setIsActive": handler.SetIsActive,
-		"/users/getReview":   handler.GetUserReviews,
+		"/repository/add":         handler.AddRepository,
+		"/repository/get":         handler.GetRepository,
+		"/repository/setSettings": handler.SetRepositorySettings,
 
-		"/pullRequest/create":   handler.CreatePR,
-		"/pullRequest/merge":    handler.MergePR,
-		"/pullRequest/reassign": handler.ReassignReviewer,
+		"/codeowners/upload": handler.UploadCodeOwners,
+		"/codeowners/get":    handler.GetCodeOwners,
 
 		"/stat/json": handler.JSONHandler,
 		"/stat/html": handler.HTMLHandler,
@@ -131,6 +222,12 @@ func (a *App) setupRoutes(handler *handlers.Handler) http.Handler {
 }
 
 func (a *App) Run() {
+	ctx, cancel := context.WithCancel(context.Background())
+	a.stopWorkers = cancel
+	go a.services.Reconciler.Run(ctx)
+	go a.services.ReviewSLA.Run(ctx)
+	go a.services.StalePRs.Run(ctx)
+
 	go a.startServer()
 	a.waitForShutdown()
 }
@@ -156,6 +253,8 @@ func (a *App) shutdown() {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	a.stopWorkers()
+
 	if err := a.server.Shutdown(ctx); err != nil {
 		slog.Error("Server forced to shutdown", "error", err)
 		os.Exit(1)
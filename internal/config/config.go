@@ -2,22 +2,29 @@ package config
 
 import (
 	"log/slog"
+	"time"
 
 	"github.com/caarlos0/env/v11"
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	ServerPort                string `env:"PORT_APP" envDefault:"8080"`
-	NameFileAllTasks          string `env:"ALL_TASKS_FILE" envDefault:"storage/AllTasks.json"`
-	NameFileProcessTasksLinks string `env:"PROCESS_LINKS_FILE" envDefault:"storage/ProcessTasksLinks.json"`
-	NameFileProcessTasksNums  string `env:"PROCESS_NUMS_FILE" envDefault:"storage/ProcessTasksNums.json"`
-	PG_DBHost                 string `env:"DB_PG_HOST" envDefault:"postgres"`
-	PG_DBUser                 string `env:"USER_DB_PG" envDefault:""`
-	PG_DBPassword             string `env:"PASS_DB_PG" envDefault:""`
-	PG_DBName                 string `env:"NAME_DB_PG" envDefault:"webdev"`
-	PG_DBSSLMode              string `env:"DB_PG_SSLMODE" envDefault:"disable"`
-	PG_PORT                   string `env:"DB_PG_PORT" envDefault:"5432"`
+	ServerPort                string        `env:"PORT_APP" envDefault:"8080"`
+	NameFileAllTasks          string        `env:"ALL_TASKS_FILE" envDefault:"storage/AllTasks.json"`
+	NameFileProcessTasksLinks string        `env:"PROCESS_LINKS_FILE" envDefault:"storage/ProcessTasksLinks.json"`
+	NameFileProcessTasksNums  string        `env:"PROCESS_NUMS_FILE" envDefault:"storage/ProcessTasksNums.json"`
+	PG_DBHost                 string        `env:"DB_PG_HOST" envDefault:"postgres"`
+	PG_DBUser                 string        `env:"USER_DB_PG" envDefault:""`
+	PG_DBPassword             string        `env:"PASS_DB_PG" envDefault:""`
+	PG_DBName                 string        `env:"NAME_DB_PG" envDefault:"webdev"`
+	PG_DBSSLMode              string        `env:"DB_PG_SSLMODE" envDefault:"disable"`
+	PG_PORT                   string        `env:"DB_PG_PORT" envDefault:"5432"`
+	ReviewerReconcileInterval time.Duration `env:"REVIEWER_RECONCILE_INTERVAL" envDefault:"1m"`
+	ReviewSLACheckInterval    time.Duration `env:"REVIEW_SLA_CHECK_INTERVAL" envDefault:"5m"`
+	WorkDayStartHour          int           `env:"WORK_DAY_START_HOUR" envDefault:"9"`
+	WorkDayEndHour            int           `env:"WORK_DAY_END_HOUR" envDefault:"18"`
+	WorkTimezone              string        `env:"WORK_TIMEZONE" envDefault:"UTC"`
+	StalePRCheckInterval      time.Duration `env:"STALE_PR_CHECK_INTERVAL" envDefault:"1h"`
 }
 
 func MustLoad() *Config {
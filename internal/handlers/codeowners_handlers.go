@@ -0,0 +1,83 @@
+package handlers
+
+/*
+	// POST /codeowners/upload
+	// GET /codeowners/get
+*/
+import (
+	"encoding/json"
+	"net/http"
+	"subscription-budget/internal/models"
+)
+
+// POST /codeowners/upload
+func (h *Handler) UploadCodeOwners(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.OwnershipFile
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if req.Scope == "" || req.ScopeRef == "" {
+		writeError(w, http.StatusBadRequest, "scope and scope_ref are required")
+		return
+	}
+
+	file, err := h.OwnershipManag.UploadOwnershipFile(r.Context(), req)
+	if err != nil {
+		switch err {
+		case models.ErrNotFound:
+			writeErrorResponse(w, http.StatusNotFound, "NOT_FOUND", "resource not found")
+		case models.ErrInvalidOwners:
+			writeErrorResponse(w, http.StatusBadRequest, "INVALID_OWNERS", "invalid scope or CODEOWNERS content")
+		default:
+			writeError(w, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response := map[string]interface{}{
+		"ownership": file,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// GET /codeowners/get
+func (h *Handler) GetCodeOwners(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	scope := r.URL.Query().Get("scope")
+	scopeRef := r.URL.Query().Get("scope_ref")
+	if scope == "" || scopeRef == "" {
+		writeError(w, http.StatusBadRequest, "scope and scope_ref parameters are required")
+		return
+	}
+
+	file, err := h.OwnershipManag.GetOwnershipFile(r.Context(), scope, scopeRef)
+	if err != nil {
+		switch err {
+		case models.ErrNotFound:
+			writeErrorResponse(w, http.StatusNotFound, "NOT_FOUND", "resource not found")
+		default:
+			writeError(w, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response := map[string]interface{}{
+		"ownership": file,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -13,6 +13,8 @@ type Handler struct {
 	TeamManag        services.TeamManager
 	UserManag        services.UserManager
 	PullRequestManag services.PullRequestManager
+	OwnershipManag   services.OwnershipManager
+	RepositoryManag  services.RepositoryManager
 	statService      *services.StatService
 	tmpl             *template.Template
 }
@@ -21,6 +23,8 @@ func NewHandler(
 	TeamManag services.TeamManager,
 	UserManag services.UserManager,
 	PullRequestManag services.PullRequestManager,
+	OwnershipManag services.OwnershipManager,
+	RepositoryManag services.RepositoryManager,
 	statService *services.StatService,
 ) (*Handler, error) {
 	tmpl := template.New("stats.html").Funcs(template.FuncMap{
@@ -37,6 +41,8 @@ func NewHandler(
 		TeamManag:        TeamManag,
 		UserManag:        UserManag,
 		PullRequestManag: PullRequestManag,
+		OwnershipManag:   OwnershipManag,
+		RepositoryManag:  RepositoryManag,
 		statService:      statService,
 		tmpl:             tmpl,
 	}, nil
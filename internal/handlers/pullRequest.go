@@ -2,13 +2,32 @@ package handlers
 
 /*
 	// POST /pullRequest/create
+	// POST /pullRequest/preview
+	// GET /pullRequest/get
+	// PATCH /pullRequest/update
 	// POST /pullRequest/merge
+	// POST /pullRequest/setDependencies
+	// GET /pullRequest/stack
+	// POST /pullRequest/ready
+	// POST /pullRequest/close
+	// POST /pullRequest/reopen
+	// POST /pullRequest/review
+	// POST /pullRequest/rerequestReview
+	// GET /pullRequest/history
+	// POST /pullRequest/comment
+	// POST /pullRequest/resolveThread
+	// POST /pullRequest/unresolveThread
+	// GET /pullRequest/threads
 	// POST /pullRequest/reassign
 
 */
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 	"subscription-budget/internal/models"
 )
 
@@ -25,8 +44,13 @@ func (h *Handler) CreatePR(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.PullRequestID == "" || req.PullRequestName == "" || req.AuthorID == "" {
-		writeError(w, http.StatusBadRequest, "pull_request_id, pull_request_name and author_id are required")
+	if (req.PullRequestID == "" && req.Number == 0) || req.PullRequestName == "" || req.AuthorID == "" {
+		writeError(w, http.StatusBadRequest, "pull_request_id (or repository and number), pull_request_name and author_id are required")
+		return
+	}
+
+	if req.ReviewerCount != nil && *req.ReviewerCount < 0 {
+		writeError(w, http.StatusBadRequest, "reviewer_count must not be negative")
 		return
 	}
 
@@ -35,6 +59,115 @@ func (h *Handler) CreatePR(w http.ResponseWriter, r *http.Request) {
 		switch err {
 		case models.ErrPRExists:
 			writeErrorResponse(w, http.StatusConflict, "PR_EXISTS", "PR id already exists")
+		case models.ErrInvalidPRRef:
+			writeErrorResponse(w, http.StatusBadRequest, "INVALID_PR_REF", "number requires repository; pull_request_id must match repository#number")
+		case models.ErrInvalidDependency:
+			writeErrorResponse(w, http.StatusBadRequest, "INVALID_DEPENDENCY", "PR cannot depend on itself or form a dependency cycle")
+		case models.ErrAuthorNotInTeam:
+			writeErrorResponse(w, http.StatusBadRequest, "AUTHOR_NOT_IN_TEAM", "author is not a member of team_name")
+		case models.ErrTeamArchived:
+			writeErrorResponse(w, http.StatusConflict, "TEAM_ARCHIVED", "team is archived")
+		case models.ErrNotFound, models.ErrNoTeam:
+			writeErrorResponse(w, http.StatusNotFound, "NOT_FOUND", "resource not found")
+		default:
+			writeError(w, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response := map[string]interface{}{
+		"pr":                pr,
+		"understaffed":      pr.MissingReviewers() > 0,
+		"missing_reviewers": pr.MissingReviewers(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// POST /pullRequest/preview
+func (h *Handler) PreviewPR(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.CreatePRRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if (req.PullRequestID == "" && req.Number == 0) || req.PullRequestName == "" || req.AuthorID == "" {
+		writeError(w, http.StatusBadRequest, "pull_request_id (or repository and number), pull_request_name and author_id are required")
+		return
+	}
+
+	if req.ReviewerCount != nil && *req.ReviewerCount < 0 {
+		writeError(w, http.StatusBadRequest, "reviewer_count must not be negative")
+		return
+	}
+
+	preview, err := h.PullRequestManag.PreviewPR(r.Context(), req)
+	if err != nil {
+		switch err {
+		case models.ErrPRExists:
+			writeErrorResponse(w, http.StatusConflict, "PR_EXISTS", "PR id already exists")
+		case models.ErrInvalidPRRef:
+			writeErrorResponse(w, http.StatusBadRequest, "INVALID_PR_REF", "number requires repository; pull_request_id must match repository#number")
+		case models.ErrInvalidDependency:
+			writeErrorResponse(w, http.StatusBadRequest, "INVALID_DEPENDENCY", "PR cannot depend on itself or form a dependency cycle")
+		case models.ErrAuthorNotInTeam:
+			writeErrorResponse(w, http.StatusBadRequest, "AUTHOR_NOT_IN_TEAM", "author is not a member of team_name")
+		case models.ErrTeamArchived:
+			writeErrorResponse(w, http.StatusConflict, "TEAM_ARCHIVED", "team is archived")
+		case models.ErrNotFound, models.ErrNoTeam:
+			writeErrorResponse(w, http.StatusNotFound, "NOT_FOUND", "resource not found")
+		default:
+			writeError(w, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response := map[string]interface{}{
+		"pr":                preview.PullRequest,
+		"reviewers":         preview.Reviewers,
+		"strategy":          preview.Strategy,
+		"understaffed":      preview.PullRequest.MissingReviewers() > 0,
+		"missing_reviewers": preview.PullRequest.MissingReviewers(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// GET /pullRequest/get
+func (h *Handler) GetPR(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	prID := r.URL.Query().Get("pull_request_id")
+	if prID == "" {
+		writeError(w, http.StatusBadRequest, "pull_request_id parameter is required")
+		return
+	}
+
+	explain := false
+	if raw := r.URL.Query().Get("explain"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "explain must be true or false")
+			return
+		}
+		explain = parsed
+	}
+
+	pr, decisions, err := h.PullRequestManag.GetPR(r.Context(), prID, explain)
+	if err != nil {
+		switch err {
 		case models.ErrNotFound:
 			writeErrorResponse(w, http.StatusNotFound, "NOT_FOUND", "resource not found")
 		default:
@@ -46,12 +179,82 @@ func (h *Handler) CreatePR(w http.ResponseWriter, r *http.Request) {
 	response := map[string]interface{}{
 		"pr": pr,
 	}
+	if explain {
+		response["decisions"] = decisions
+	}
 
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
+	w.Header().Set("ETag", prETag(pr))
 	json.NewEncoder(w).Encode(response)
 }
 
+// PATCH /pullRequest/update
+// Версия передается полем version или заголовком If-Match (ETag из /pullRequest/get)
+func (h *Handler) UpdatePR(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch && r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.UpdatePRRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if req.PullRequestID == "" {
+		writeError(w, http.StatusBadRequest, "pull_request_id is required")
+		return
+	}
+
+	if req.PullRequestName != nil && strings.TrimSpace(*req.PullRequestName) == "" {
+		writeError(w, http.StatusBadRequest, "pull_request_name must not be empty")
+		return
+	}
+
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		version, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(ifMatch, "W/"), `"`))
+		if err != nil || (req.Version != 0 && req.Version != version) {
+			writeError(w, http.StatusBadRequest, "If-Match must be an ETag from /pullRequest/get matching version")
+			return
+		}
+		req.Version = version
+	}
+
+	if req.Version <= 0 {
+		writeErrorResponse(w, http.StatusPreconditionRequired, "VERSION_REQUIRED", "version or If-Match header is required")
+		return
+	}
+
+	pr, err := h.PullRequestManag.UpdatePR(r.Context(), req)
+	if err != nil {
+		switch err {
+		case models.ErrNotFound:
+			writeErrorResponse(w, http.StatusNotFound, "NOT_FOUND", "resource not found")
+		case models.ErrVersionConflict:
+			writeErrorResponse(w, http.StatusPreconditionFailed, "VERSION_CONFLICT", "PR was changed by someone else, reload it and retry")
+		case models.ErrPRMerged:
+			writeErrorResponse(w, http.StatusConflict, "PR_MERGED", "cannot edit merged PR")
+		default:
+			writeError(w, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response := map[string]interface{}{
+		"pr": pr,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", prETag(pr))
+	json.NewEncoder(w).Encode(response)
+}
+
+// prETag - ETag PR по версии его метаданных
+func prETag(pr *models.PullRequest) string {
+	return `"` + strconv.Itoa(pr.Version) + `"`
+}
+
 // POST /pullRequest/merge
 func (h *Handler) MergePR(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -59,10 +262,79 @@ func (h *Handler) MergePR(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var req struct {
-		PullRequestID string `json:"pull_request_id"`
+	var req models.MergePRRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
 	}
 
+	if req.PullRequestID == "" {
+		writeError(w, http.StatusBadRequest, "pull_request_id is required")
+		return
+	}
+
+	if req.Force && req.Actor == "" {
+		writeError(w, http.StatusBadRequest, "actor is required for force merge")
+		return
+	}
+
+	pr, err := h.PullRequestManag.MergePR(r.Context(), req)
+	if err != nil {
+		var blocked *models.MergeBlockedError
+		if errors.As(err, &blocked) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error": map[string]interface{}{
+					"code":    "MERGE_BLOCKED",
+					"message": "PR does not satisfy the team merge policy",
+					"unmet":   blocked.Unmet,
+				},
+			})
+			return
+		}
+
+		var dependency *models.DependencyOpenError
+		if errors.As(err, &dependency) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error": map[string]interface{}{
+					"code":         "DEPENDENCY_OPEN",
+					"message":      "parent PRs must be merged first",
+					"open_parents": dependency.OpenParents,
+				},
+			})
+			return
+		}
+
+		switch err {
+		case models.ErrNotFound, models.ErrNoTeam:
+			writeErrorResponse(w, http.StatusNotFound, "NOT_FOUND", "resource not found")
+		case models.ErrInvalidTransition:
+			writeErrorResponse(w, http.StatusConflict, "INVALID_TRANSITION", "only OPEN or REOPENED PR can be merged")
+		default:
+			writeError(w, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response := map[string]interface{}{
+		"pr": pr,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// POST /pullRequest/setDependencies
+func (h *Handler) SetDependencies(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.SetDependenciesRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid request body")
 		return
@@ -73,11 +345,15 @@ func (h *Handler) MergePR(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	pr, err := h.PullRequestManag.MergePR(r.Context(), req.PullRequestID)
+	pr, err := h.PullRequestManag.SetDependencies(r.Context(), req)
 	if err != nil {
 		switch err {
 		case models.ErrNotFound:
 			writeErrorResponse(w, http.StatusNotFound, "NOT_FOUND", "resource not found")
+		case models.ErrInvalidDependency:
+			writeErrorResponse(w, http.StatusBadRequest, "INVALID_DEPENDENCY", "PR cannot depend on itself or form a dependency cycle")
+		case models.ErrPRMerged:
+			writeErrorResponse(w, http.StatusConflict, "PR_MERGED", "cannot change dependencies of merged PR")
 		default:
 			writeError(w, http.StatusInternalServerError, "internal server error")
 		}
@@ -92,6 +368,39 @@ func (h *Handler) MergePR(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// GET /pullRequest/stack
+func (h *Handler) GetPRStack(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	prID := r.URL.Query().Get("pull_request_id")
+	if prID == "" {
+		writeError(w, http.StatusBadRequest, "pull_request_id parameter is required")
+		return
+	}
+
+	stack, err := h.PullRequestManag.GetPRStack(r.Context(), prID)
+	if err != nil {
+		switch err {
+		case models.ErrNotFound:
+			writeErrorResponse(w, http.StatusNotFound, "NOT_FOUND", "resource not found")
+		default:
+			writeError(w, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response := map[string]interface{}{
+		"pull_request_id": prID,
+		"stack":           stack,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
 // POST /pullRequest/reassign
 func (h *Handler) ReassignReviewer(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -113,14 +422,16 @@ func (h *Handler) ReassignReviewer(w http.ResponseWriter, r *http.Request) {
 	pr, newReviewer, err := h.PullRequestManag.ReassignReviewer(r.Context(), req)
 	if err != nil {
 		switch err {
-		case models.ErrNotFound:
+		case models.ErrNotFound, models.ErrNoTeam:
 			writeErrorResponse(w, http.StatusNotFound, "NOT_FOUND", "resource not found")
 		case models.ErrPRMerged:
 			writeErrorResponse(w, http.StatusConflict, "PR_MERGED", "cannot reassign on merged PR")
+		case models.ErrPRNotOpen:
+			writeErrorResponse(w, http.StatusConflict, "PR_NOT_OPEN", "cannot reassign on draft or closed PR")
 		case models.ErrNotAssigned:
 			writeErrorResponse(w, http.StatusConflict, "NOT_ASSIGNED", "reviewer is not assigned to this PR")
 		case models.ErrNoCandidate:
-			writeErrorResponse(w, http.StatusConflict, "NO_CANDIDATE", "no active replacement candidate in team")
+			writeErrorResponse(w, http.StatusConflict, "NO_CANDIDATE", "no active replacement candidate in team or its fallback teams")
 		default:
 			writeError(w, http.StatusInternalServerError, "internal server error")
 		}
@@ -130,6 +441,349 @@ func (h *Handler) ReassignReviewer(w http.ResponseWriter, r *http.Request) {
 	response := map[string]interface{}{
 		"pr":          pr,
 		"replaced_by": newReviewer,
+		"cross_team":  contains(pr.CrossTeamReviewers, newReviewer),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func contains(slice []string, item string) bool {
+	for _, s := range slice {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
+
+// POST /pullRequest/ready
+func (h *Handler) ReadyForReview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.ReadyPRRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if req.PullRequestID == "" {
+		writeError(w, http.StatusBadRequest, "pull_request_id is required")
+		return
+	}
+
+	pr, err := h.PullRequestManag.ReadyForReview(r.Context(), req)
+	if err != nil {
+		switch err {
+		case models.ErrNotFound, models.ErrNoTeam:
+			writeErrorResponse(w, http.StatusNotFound, "NOT_FOUND", "resource not found")
+		case models.ErrInvalidTransition:
+			writeErrorResponse(w, http.StatusConflict, "INVALID_TRANSITION", "only DRAFT PR can be marked ready for review")
+		default:
+			writeError(w, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response := map[string]interface{}{
+		"pr":                pr,
+		"understaffed":      pr.MissingReviewers() > 0,
+		"missing_reviewers": pr.MissingReviewers(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// POST /pullRequest/close
+func (h *Handler) ClosePR(w http.ResponseWriter, r *http.Request) {
+	h.changePRStatus(w, r, h.PullRequestManag.ClosePR, "only DRAFT, OPEN or REOPENED PR can be closed")
+}
+
+// POST /pullRequest/reopen
+func (h *Handler) ReopenPR(w http.ResponseWriter, r *http.Request) {
+	h.changePRStatus(w, r, h.PullRequestManag.ReopenPR, "only CLOSED PR can be reopened")
+}
+
+// changePRStatus - общий обработчик переходов, которым нужен только pull_request_id
+func (h *Handler) changePRStatus(
+	w http.ResponseWriter,
+	r *http.Request,
+	transition func(ctx context.Context, prID string) (*models.PullRequest, error),
+	invalidTransitionMessage string,
+) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		PullRequestID string `json:"pull_request_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if req.PullRequestID == "" {
+		writeError(w, http.StatusBadRequest, "pull_request_id is required")
+		return
+	}
+
+	pr, err := transition(r.Context(), req.PullRequestID)
+	if err != nil {
+		switch err {
+		case models.ErrNotFound:
+			writeErrorResponse(w, http.StatusNotFound, "NOT_FOUND", "resource not found")
+		case models.ErrInvalidTransition:
+			writeErrorResponse(w, http.StatusConflict, "INVALID_TRANSITION", invalidTransitionMessage)
+		default:
+			writeError(w, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response := map[string]interface{}{
+		"pr": pr,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// POST /pullRequest/review
+func (h *Handler) SubmitReview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.SubmitReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if req.PullRequestID == "" || req.ReviewerID == "" || req.State == "" {
+		writeError(w, http.StatusBadRequest, "pull_request_id, reviewer_id and state are required")
+		return
+	}
+
+	review, err := h.PullRequestManag.SubmitReview(r.Context(), req)
+	if err != nil {
+		writeReviewError(w, err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"review": review,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// POST /pullRequest/rerequestReview
+func (h *Handler) RerequestReview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		PullRequestID string `json:"pull_request_id"`
+		ReviewerID    string `json:"reviewer_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if req.PullRequestID == "" || req.ReviewerID == "" {
+		writeError(w, http.StatusBadRequest, "pull_request_id and reviewer_id are required")
+		return
+	}
+
+	review, err := h.PullRequestManag.RerequestReview(r.Context(), req.PullRequestID, req.ReviewerID)
+	if err != nil {
+		writeReviewError(w, err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"review": review,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func writeReviewError(w http.ResponseWriter, err error) {
+	switch err {
+	case models.ErrNotFound:
+		writeErrorResponse(w, http.StatusNotFound, "NOT_FOUND", "resource not found")
+	case models.ErrInvalidReviewState:
+		writeErrorResponse(w, http.StatusBadRequest, "INVALID_REVIEW_STATE", "state must be APPROVED, CHANGES_REQUESTED or COMMENTED")
+	case models.ErrPRMerged:
+		writeErrorResponse(w, http.StatusConflict, "PR_MERGED", "cannot review merged PR")
+	case models.ErrPRNotOpen:
+		writeErrorResponse(w, http.StatusConflict, "PR_NOT_OPEN", "cannot review draft or closed PR")
+	case models.ErrNotAssigned:
+		writeErrorResponse(w, http.StatusConflict, "NOT_ASSIGNED", "reviewer is not assigned to this PR")
+	default:
+		writeError(w, http.StatusInternalServerError, "internal server error")
+	}
+}
+
+// POST /pullRequest/comment
+func (h *Handler) AddComment(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.AddCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if req.AuthorID == "" || (req.PullRequestID == "" && req.ThreadID == 0) {
+		writeError(w, http.StatusBadRequest, "author_id and pull_request_id or thread_id are required")
+		return
+	}
+
+	thread, err := h.PullRequestManag.AddComment(r.Context(), req)
+	if err != nil {
+		writeThreadError(w, err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"thread": thread,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// POST /pullRequest/resolveThread
+func (h *Handler) ResolveThread(w http.ResponseWriter, r *http.Request) {
+	h.changeThreadResolution(w, r, h.PullRequestManag.ResolveThread)
+}
+
+// POST /pullRequest/unresolveThread
+func (h *Handler) UnresolveThread(w http.ResponseWriter, r *http.Request) {
+	h.changeThreadResolution(w, r, h.PullRequestManag.UnresolveThread)
+}
+
+func (h *Handler) changeThreadResolution(
+	w http.ResponseWriter,
+	r *http.Request,
+	change func(ctx context.Context, req models.ResolveThreadRequest) (*models.CommentThread, error),
+) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.ResolveThreadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if req.ThreadID == 0 || req.UserID == "" {
+		writeError(w, http.StatusBadRequest, "thread_id and user_id are required")
+		return
+	}
+
+	thread, err := change(r.Context(), req)
+	if err != nil {
+		writeThreadError(w, err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"thread": thread,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// GET /pullRequest/threads
+func (h *Handler) GetCommentThreads(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	prID := r.URL.Query().Get("pull_request_id")
+	if prID == "" {
+		writeError(w, http.StatusBadRequest, "pull_request_id parameter is required")
+		return
+	}
+
+	threads, err := h.PullRequestManag.GetCommentThreads(r.Context(), prID)
+	if err != nil {
+		writeThreadError(w, err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"pull_request_id": prID,
+		"threads":         threads,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func writeThreadError(w http.ResponseWriter, err error) {
+	switch err {
+	case models.ErrNotFound:
+		writeErrorResponse(w, http.StatusNotFound, "NOT_FOUND", "resource not found")
+	case models.ErrInvalidComment:
+		writeErrorResponse(w, http.StatusBadRequest, "INVALID_COMMENT", "body is required; line requires file_path and must be positive; replies cannot set file_path or line")
+	default:
+		writeError(w, http.StatusInternalServerError, "internal server error")
+	}
+}
+
+// GET /pullRequest/history
+func (h *Handler) GetPRHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	prID := r.URL.Query().Get("pull_request_id")
+	if prID == "" {
+		writeError(w, http.StatusBadRequest, "pull_request_id parameter is required")
+		return
+	}
+
+	history, err := h.PullRequestManag.GetPRHistory(r.Context(), prID)
+	if err != nil {
+		switch err {
+		case models.ErrNotFound:
+			writeErrorResponse(w, http.StatusNotFound, "NOT_FOUND", "resource not found")
+		default:
+			writeError(w, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response := map[string]interface{}{
+		"pull_request_id": prID,
+		"history":         history,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -0,0 +1,139 @@
+package handlers
+
+/*
+	// POST /repository/add
+	// GET /repository/get
+	// POST /repository/setSettings
+*/
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"subscription-budget/internal/models"
+)
+
+// POST /repository/add
+func (h *Handler) AddRepository(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.Repository
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if req.Name == "" {
+		writeError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+
+	// "#" разделяет репозиторий и номер в id PR
+	if strings.Contains(req.Name, "#") {
+		writeError(w, http.StatusBadRequest, "name must not contain '#'")
+		return
+	}
+
+	if req.ReviewerCount != nil && *req.ReviewerCount < 0 {
+		writeError(w, http.StatusBadRequest, "reviewer_count must not be negative")
+		return
+	}
+
+	repo, err := h.RepositoryManag.CreateRepository(r.Context(), req)
+	if err != nil {
+		switch err {
+		case models.ErrRepositoryExists:
+			writeErrorResponse(w, http.StatusConflict, "REPOSITORY_EXISTS", "repository already exists")
+		default:
+			writeRepositoryError(w, err)
+		}
+		return
+	}
+
+	response := map[string]interface{}{
+		"repository": repo,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// GET /repository/get
+func (h *Handler) GetRepository(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "name parameter is required")
+		return
+	}
+
+	repo, err := h.RepositoryManag.GetRepository(r.Context(), name)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"repository": repo,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// POST /repository/setSettings
+func (h *Handler) SetRepositorySettings(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.RepositorySettingsUpdate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if req.Name == "" {
+		writeError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+
+	if req.ReviewerCount != nil && *req.ReviewerCount < 0 {
+		writeError(w, http.StatusBadRequest, "reviewer_count must not be negative")
+		return
+	}
+
+	repo, err := h.RepositoryManag.UpdateRepositorySettings(r.Context(), req)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"repository": repo,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func writeRepositoryError(w http.ResponseWriter, err error) {
+	switch err {
+	case models.ErrNotFound:
+		writeErrorResponse(w, http.StatusNotFound, "NOT_FOUND", "resource not found")
+	case models.ErrUnknownStrategy:
+		writeErrorResponse(w, http.StatusBadRequest, "UNKNOWN_STRATEGY", "unknown reviewer strategy")
+	case models.ErrTeamArchived:
+		writeErrorResponse(w, http.StatusConflict, "TEAM_ARCHIVED", "team is archived")
+	default:
+		writeError(w, http.StatusInternalServerError, "internal server error")
+	}
+}
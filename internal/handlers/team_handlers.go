@@ -3,10 +3,22 @@ package handlers
 /*
 	// POST /team/add
 	// GET /team/get
+	// POST /team/update
+	// GET /team/getSettings
+	// POST /team/setSettings
+	// GET /team/stalePRs
+	// POST /team/addMember
+	// POST /team/removeMember
+	// POST /team/moveMember
+	// POST /team/setPrimary
+	// POST /team/archive
+	// POST /team/delete
 */
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 	"subscription-budget/internal/models"
 )
 
@@ -18,8 +30,13 @@ func (h *Handler) AddTeam(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var request struct {
-		TeamName string        `json:"team_name"`
-		Members  []models.User `json:"members"`
+		TeamName     string        `json:"team_name"`
+		ParentName   string        `json:"parent_name"`
+		Description  string        `json:"description"`
+		LeadUserID   string        `json:"lead_user_id"`
+		ChatChannel  string        `json:"chat_channel"`
+		ReviewPolicy string        `json:"review_policy"`
+		Members      []models.User `json:"members"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
@@ -32,8 +49,13 @@ func (h *Handler) AddTeam(w http.ResponseWriter, r *http.Request) {
 	}
 
 	team := models.Team{
-		TeamName: request.TeamName,
-		Members:  request.Members,
+		TeamName:     request.TeamName,
+		ParentName:   request.ParentName,
+		Description:  request.Description,
+		LeadUserID:   request.LeadUserID,
+		ChatChannel:  request.ChatChannel,
+		ReviewPolicy: request.ReviewPolicy,
+		Members:      request.Members,
 	}
 
 	createdTeam, err := h.TeamManag.CreateTeam(r.Context(), team)
@@ -41,8 +63,12 @@ func (h *Handler) AddTeam(w http.ResponseWriter, r *http.Request) {
 		switch err {
 		case models.ErrTeamExists:
 			writeErrorResponse(w, http.StatusBadRequest, "TEAM_EXISTS", "team_name already exists")
+		case models.ErrInvalidLead:
+			writeErrorResponse(w, http.StatusBadRequest, "INVALID_LEAD", "lead must be a team member")
+		case models.ErrInvalidParent:
+			writeErrorResponse(w, http.StatusBadRequest, "INVALID_PARENT", "parent must be another active team outside the team's subtree")
 		default:
-			writeError(w, http.StatusInternalServerError, "internal server error")
+			writeMemberError(w, err)
 		}
 		return
 	}
@@ -69,7 +95,17 @@ func (h *Handler) GetTeam(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	team, err := h.TeamManag.GetTeam(r.Context(), teamName)
+	includeChildren := false
+	if raw := r.URL.Query().Get("include_children"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "include_children must be true or false")
+			return
+		}
+		includeChildren = parsed
+	}
+
+	team, err := h.TeamManag.GetTeam(r.Context(), teamName, includeChildren)
 	if err != nil {
 		switch err {
 		case models.ErrNotFound:
@@ -83,3 +119,398 @@ func (h *Handler) GetTeam(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(team)
 }
+
+// POST /team/update
+func (h *Handler) UpdateTeam(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodPatch {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.TeamUpdate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if req.TeamName == "" {
+		writeError(w, http.StatusBadRequest, "team_name is required")
+		return
+	}
+
+	team, err := h.TeamManag.UpdateTeam(r.Context(), req)
+	if err != nil {
+		switch err {
+		case models.ErrNotFound:
+			writeErrorResponse(w, http.StatusNotFound, "NOT_FOUND", "resource not found")
+		case models.ErrInvalidLead:
+			writeErrorResponse(w, http.StatusBadRequest, "INVALID_LEAD", "lead must be a team member and is required for lead approval")
+		case models.ErrInvalidParent:
+			writeErrorResponse(w, http.StatusBadRequest, "INVALID_PARENT", "parent must be another active team outside the team's subtree")
+		default:
+			writeError(w, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response := map[string]interface{}{
+		"team": team,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// GET /team/getSettings
+func (h *Handler) GetTeamSettings(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	teamName := r.URL.Query().Get("team_name")
+	if teamName == "" {
+		writeError(w, http.StatusBadRequest, "team_name parameter is required")
+		return
+	}
+
+	settings, err := h.TeamManag.GetTeamSettings(r.Context(), teamName)
+	if err != nil {
+		switch err {
+		case models.ErrNotFound:
+			writeErrorResponse(w, http.StatusNotFound, "NOT_FOUND", "resource not found")
+		default:
+			writeError(w, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response := map[string]interface{}{
+		"settings": settings,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// POST /team/setSettings
+func (h *Handler) SetTeamSettings(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.TeamSettingsUpdate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if req.TeamName == "" {
+		writeError(w, http.StatusBadRequest, "team_name is required")
+		return
+	}
+
+	if req.ReviewerCount != nil && *req.ReviewerCount < 0 {
+		writeError(w, http.StatusBadRequest, "reviewer_count must not be negative")
+		return
+	}
+
+	if req.MaxOpenReviews != nil && *req.MaxOpenReviews < 0 {
+		writeError(w, http.StatusBadRequest, "max_open_reviews must not be negative")
+		return
+	}
+
+	if req.MinApprovals != nil && *req.MinApprovals < 0 {
+		writeError(w, http.StatusBadRequest, "min_approvals must not be negative")
+		return
+	}
+
+	if req.ReviewSLAHours != nil && *req.ReviewSLAHours < 0 {
+		writeError(w, http.StatusBadRequest, "review_sla_hours must not be negative")
+		return
+	}
+
+	if req.StaleAfterDays != nil && *req.StaleAfterDays < 1 {
+		writeError(w, http.StatusBadRequest, "stale_after_days must be positive")
+		return
+	}
+
+	if req.StaleCloseAfterDays != nil && *req.StaleCloseAfterDays < 0 {
+		writeError(w, http.StatusBadRequest, "stale_close_after_days must not be negative")
+		return
+	}
+
+	settings, err := h.TeamManag.UpdateTeamSettings(r.Context(), req)
+	if err != nil {
+		switch err {
+		case models.ErrNotFound:
+			writeErrorResponse(w, http.StatusNotFound, "NOT_FOUND", "resource not found")
+		case models.ErrUnknownStrategy:
+			writeErrorResponse(w, http.StatusBadRequest, "UNKNOWN_STRATEGY", "unknown reviewer strategy")
+		case models.ErrInvalidFallback:
+			writeErrorResponse(w, http.StatusBadRequest, "INVALID_FALLBACK", "fallback teams must be distinct active teams and differ from the team itself")
+		case models.ErrInvalidMergePolicy:
+			writeErrorResponse(w, http.StatusBadRequest, "INVALID_MERGE_POLICY", "lead must be a team member and is required for lead approval")
+		default:
+			writeError(w, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response := map[string]interface{}{
+		"settings": settings,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// GET /team/stalePRs
+func (h *Handler) GetStalePRs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	teamName := r.URL.Query().Get("team_name")
+	if teamName == "" {
+		writeError(w, http.StatusBadRequest, "team_name parameter is required")
+		return
+	}
+
+	prs, err := h.PullRequestManag.GetStalePRs(r.Context(), teamName)
+	if err != nil {
+		switch err {
+		case models.ErrNotFound:
+			writeErrorResponse(w, http.StatusNotFound, "NOT_FOUND", "resource not found")
+		default:
+			writeError(w, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response := map[string]interface{}{
+		"team_name":     teamName,
+		"pull_requests": prs,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// POST /team/addMember
+func (h *Handler) AddMember(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.AddMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if req.TeamName == "" || req.User.UserID == "" {
+		writeError(w, http.StatusBadRequest, "team_name and user.user_id are required")
+		return
+	}
+
+	team, err := h.TeamManag.AddMember(r.Context(), req)
+	if err != nil {
+		writeMemberError(w, err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"team": team,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// POST /team/removeMember
+func (h *Handler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.RemoveMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if req.TeamName == "" || req.UserID == "" {
+		writeError(w, http.StatusBadRequest, "team_name and user_id are required")
+		return
+	}
+
+	reassigned, err := h.TeamManag.RemoveMember(r.Context(), req)
+	if err != nil {
+		writeMemberError(w, err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"user_id":    req.UserID,
+		"team_name":  req.TeamName,
+		"reassigned": reassigned,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// POST /team/moveMember
+func (h *Handler) MoveMember(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.MoveMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if req.UserID == "" || req.ToTeam == "" {
+		writeError(w, http.StatusBadRequest, "user_id and to_team are required")
+		return
+	}
+
+	team, reassigned, err := h.TeamManag.MoveMember(r.Context(), req)
+	if err != nil {
+		writeMemberError(w, err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"team":       team,
+		"reassigned": reassigned,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func writeMemberError(w http.ResponseWriter, err error) {
+	var openReviews *models.OpenReviewsError
+	if errors.As(err, &openReviews) {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": map[string]interface{}{
+				"code":             "OPEN_REVIEWS",
+				"message":          "user has open reviews; set reassign_reviews or confirm",
+				"pull_request_ids": openReviews.PullRequestIDs,
+			},
+		})
+		return
+	}
+
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		writeErrorResponse(w, http.StatusNotFound, "NOT_FOUND", "resource not found")
+	case errors.Is(err, models.ErrAlreadyMember):
+		writeErrorResponse(w, http.StatusConflict, "ALREADY_MEMBER", "user is already a member of the team")
+	case errors.Is(err, models.ErrNotMember):
+		writeErrorResponse(w, http.StatusNotFound, "NOT_MEMBER", "user is not a member of the team")
+	case errors.Is(err, models.ErrTeamLead):
+		writeErrorResponse(w, http.StatusConflict, "TEAM_LEAD", "user is the team lead; change lead_user_id first")
+	case errors.Is(err, models.ErrTeamArchived):
+		writeErrorResponse(w, http.StatusConflict, "TEAM_ARCHIVED", "team is archived")
+	case errors.Is(err, models.ErrAmbiguousTeam):
+		writeErrorResponse(w, http.StatusBadRequest, "AMBIGUOUS_TEAM", "user belongs to several teams; from_team is required")
+	default:
+		writeError(w, http.StatusInternalServerError, "internal server error")
+	}
+}
+
+// POST /team/setPrimary
+func (h *Handler) SetPrimaryTeam(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.SetPrimaryTeamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if req.UserID == "" {
+		writeError(w, http.StatusBadRequest, "user_id is required")
+		return
+	}
+
+	memberships, err := h.TeamManag.SetPrimaryTeam(r.Context(), req)
+	if err != nil {
+		writeMemberError(w, err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"user_id": req.UserID,
+		"teams":   memberships,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// POST /team/archive
+func (h *Handler) ArchiveTeam(w http.ResponseWriter, r *http.Request) {
+	h.archiveTeam(w, r, false)
+}
+
+// POST /team/delete
+func (h *Handler) DeleteTeam(w http.ResponseWriter, r *http.Request) {
+	h.archiveTeam(w, r, true)
+}
+
+// archiveTeam - без apply возвращает план, ничего не меняя
+func (h *Handler) archiveTeam(w http.ResponseWriter, r *http.Request, deleteTeam bool) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.ArchiveTeamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if req.TeamName == "" {
+		writeError(w, http.StatusBadRequest, "team_name is required")
+		return
+	}
+	req.Delete = deleteTeam
+
+	plan, err := h.TeamManag.ArchiveTeam(r.Context(), req)
+	if err != nil {
+		switch err {
+		case models.ErrNotFound:
+			writeErrorResponse(w, http.StatusNotFound, "NOT_FOUND", "resource not found")
+		case models.ErrTeamArchived:
+			writeErrorResponse(w, http.StatusConflict, "TEAM_ARCHIVED", "team is archived")
+		case models.ErrInvalidMoveTarget:
+			writeErrorResponse(w, http.StatusBadRequest, "INVALID_MOVE_TARGET", "move_members_to must be another active team")
+		default:
+			writeError(w, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response := map[string]interface{}{
+		"plan": plan,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
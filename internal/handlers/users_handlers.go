@@ -2,6 +2,10 @@ package handlers
 /*
 	// POST /users/setIsActive
 	// GET /users/getReview
+	// POST /users/setSkills
+	// POST /users/addAbsence
+	// GET /users/getAbsences
+	// POST /users/deleteAbsence
 */
 import (
 	"encoding/json"
@@ -17,8 +21,9 @@ func (h *Handler) SetIsActive(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req struct {
-		UserID   string `json:"user_id"`
-		IsActive bool   `json:"is_active"`
+		UserID          string `json:"user_id"`
+		IsActive        bool   `json:"is_active"`
+		ReassignReviews *bool  `json:"reassign_reviews"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -31,7 +36,12 @@ func (h *Handler) SetIsActive(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := h.UserManag.SetUserActive(r.Context(), req.UserID, req.IsActive)
+	reassignReviews := true
+	if req.ReassignReviews != nil {
+		reassignReviews = *req.ReassignReviews
+	}
+
+	user, reassigned, err := h.UserManag.SetUserActive(r.Context(), req.UserID, req.IsActive, reassignReviews)
 	if err != nil {
 		switch err {
 		case models.ErrNotFound:
@@ -47,8 +57,11 @@ func (h *Handler) SetIsActive(w http.ResponseWriter, r *http.Request) {
 			"user_id":   user.UserID,
 			"username":  user.Username,
 			"team_name": user.TeamName,
+			"teams":     user.Teams,
 			"is_active": user.IsActive,
+			"skills":    user.Skills,
 		},
+		"reassigned": reassigned,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -68,7 +81,9 @@ func (h *Handler) GetUserReviews(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	prs, err := h.PullRequestManag.GetUserReviews(r.Context(), userID)
+	repository := r.URL.Query().Get("repository")
+
+	prs, err := h.PullRequestManag.GetUserReviews(r.Context(), userID, repository)
 	if err != nil {
 		switch err {
 		case models.ErrNotFound:
@@ -87,3 +102,158 @@ func (h *Handler) GetUserReviews(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
+
+// POST /users/setSkills
+func (h *Handler) SetSkills(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		UserID string   `json:"user_id"`
+		Skills []string `json:"skills"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if req.UserID == "" {
+		writeError(w, http.StatusBadRequest, "user_id is required")
+		return
+	}
+
+	user, err := h.UserManag.SetUserSkills(r.Context(), req.UserID, req.Skills)
+	if err != nil {
+		switch err {
+		case models.ErrNotFound:
+			writeErrorResponse(w, http.StatusNotFound, "NOT_FOUND", "resource not found")
+		default:
+			writeError(w, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response := map[string]interface{}{
+		"user": user,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// POST /users/addAbsence
+func (h *Handler) AddAbsence(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.UserAbsence
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if req.UserID == "" || req.StartsAt.IsZero() || req.EndsAt.IsZero() {
+		writeError(w, http.StatusBadRequest, "user_id, starts_at and ends_at are required")
+		return
+	}
+
+	absence, err := h.UserManag.AddAbsence(r.Context(), req)
+	if err != nil {
+		switch err {
+		case models.ErrNotFound:
+			writeErrorResponse(w, http.StatusNotFound, "NOT_FOUND", "resource not found")
+		case models.ErrInvalidAbsence:
+			writeErrorResponse(w, http.StatusBadRequest, "INVALID_ABSENCE", "ends_at must be after starts_at")
+		default:
+			writeError(w, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response := map[string]interface{}{
+		"absence": absence,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// GET /users/getAbsences
+func (h *Handler) GetAbsences(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		writeError(w, http.StatusBadRequest, "user_id parameter is required")
+		return
+	}
+
+	absences, err := h.UserManag.GetAbsences(r.Context(), userID)
+	if err != nil {
+		switch err {
+		case models.ErrNotFound:
+			writeErrorResponse(w, http.StatusNotFound, "NOT_FOUND", "resource not found")
+		default:
+			writeError(w, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response := map[string]interface{}{
+		"user_id":  userID,
+		"absences": absences,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// POST /users/deleteAbsence
+func (h *Handler) DeleteAbsence(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		AbsenceID int64 `json:"absence_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if req.AbsenceID == 0 {
+		writeError(w, http.StatusBadRequest, "absence_id is required")
+		return
+	}
+
+	err := h.UserManag.DeleteAbsence(r.Context(), req.AbsenceID)
+	if err != nil {
+		switch err {
+		case models.ErrNotFound:
+			writeErrorResponse(w, http.StatusNotFound, "NOT_FOUND", "resource not found")
+		default:
+			writeError(w, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response := map[string]interface{}{
+		"absence_id": req.AbsenceID,
+		"deleted":    true,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
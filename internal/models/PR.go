@@ -3,27 +3,160 @@ package models
 import "time"
 
 type PullRequest struct {
-	PullRequestID     string     `json:"pull_request_id"`
-	PullRequestName   string     `json:"pull_request_name"`
-	AuthorID          string     `json:"author_id"`
-	Status            string     `json:"status"`
-	AssignedReviewers []string   `json:"assigned_reviewers"`
-	CreatedAt         time.Time  `json:"createdAt,omitempty"`
-	MergedAt          *time.Time `json:"mergedAt,omitempty"`
+	PullRequestID      string     `json:"pull_request_id"`
+	PullRequestName    string     `json:"pull_request_name"`
+	Description        string     `json:"description"`
+	AuthorID           string     `json:"author_id"`
+	Status             string     `json:"status"`
+	AssignedReviewers  []string   `json:"assigned_reviewers"`
+	TargetReviewers    int        `json:"target_reviewers"`
+	CrossTeamReviewers []string   `json:"cross_team_reviewers"`
+	Labels             []string   `json:"labels"`
+	Repository         string     `json:"repository,omitempty"`
+	Number             int        `json:"number,omitempty"`
+	TeamName           string     `json:"team_name,omitempty"`
+	Version            int        `json:"version"`
+	DependsOn          []string   `json:"depends_on"`
+	CreatedAt          time.Time  `json:"createdAt,omitempty"`
+	MergedAt           *time.Time `json:"mergedAt,omitempty"`
+	ClosedAt           *time.Time `json:"closedAt,omitempty"`
+
+	// Reviews заполняется только при получении одного PR
+	Reviews []PullRequestReview `json:"reviews,omitempty"`
 }
 type PullRequestShort struct {
 	PullRequestID   string `json:"pull_request_id"`
 	PullRequestName string `json:"pull_request_name"`
 	AuthorID        string `json:"author_id"`
 	Status          string `json:"status"`
+	Repository      string `json:"repository,omitempty"`
+	ReviewState     string `json:"review_state"`
+	ReviewOwed      bool   `json:"review_owed"`
+	ReviewOverdue   bool   `json:"review_overdue"`
 }
+
+// CreatePRRequest - PR в зарегистрированном репозитории задается через Repository и Number,
+// его id тогда RepositoryPRID(repository, number). Без Number - глобальный PullRequestID,
+// а незарегистрированный Repository используется только для поиска CODEOWNERS
 type CreatePRRequest struct {
-	PullRequestID   string `json:"pull_request_id"`
-	PullRequestName string `json:"pull_request_name"`
-	AuthorID        string `json:"author_id"`
+	PullRequestID   string   `json:"pull_request_id"`
+	PullRequestName string   `json:"pull_request_name"`
+	Description     string   `json:"description,omitempty"`
+	AuthorID        string   `json:"author_id"`
+	ReviewerCount   *int     `json:"reviewer_count,omitempty"`
+	Repository      string   `json:"repository,omitempty"`
+	Number          int      `json:"number,omitempty"`
+	ChangedFiles    []string `json:"changed_files,omitempty"`
+	Labels          []string `json:"labels,omitempty"`
+	Draft           bool     `json:"draft,omitempty"`
+	DependsOn       []string `json:"depends_on,omitempty"`
+
+	// TeamName - из пула какой команды выбирать ревьюеров (для авторов из нескольких команд).
+	// Автор должен в ней состоять. Перекрывает команду по умолчанию репозитория и основную команду автора
+	TeamName string `json:"team_name,omitempty"`
 }
 
 type ReassignRequest struct {
 	PullRequestID string `json:"pull_request_id"`
 	OldUserID     string `json:"old_user_id"`
 }
+
+// MissingReviewers - сколько ревьюеров не хватило до целевого числа
+func (pr *PullRequest) MissingReviewers() int {
+	if missing := pr.TargetReviewers - len(pr.AssignedReviewers); missing > 0 {
+		return missing
+	}
+	return 0
+}
+
+// ReviewReassignment - ревью, снятое с пользователя; ReplacedBy пустой, если замены не нашлось
+type ReviewReassignment struct {
+	PullRequestID string `json:"pull_request_id"`
+	OldUserID     string `json:"old_user_id"`
+	ReplacedBy    string `json:"replaced_by"`
+	CrossTeam     bool   `json:"cross_team"`
+}
+
+// Откуда взят ревьюер при назначении
+const (
+	ReviewerSourceTeamLead  = "team_lead"
+	ReviewerSourceCodeOwner = "code_owner"
+	ReviewerSourceTeam      = "team"
+	ReviewerSourceFallback  = "fallback_team"
+	ReviewerSourceParent    = "parent_team"
+)
+
+// ReviewerPick - выбранный ревьюер и причина выбора (для предпросмотра)
+type ReviewerPick struct {
+	UserID        string   `json:"user_id"`
+	Source        string   `json:"source"`
+	TeamName      string   `json:"team_name"`
+	MatchedLabels []string `json:"matched_labels"`
+}
+
+// PRPreview - результат dry-run создания PR, в базе ничего не меняется
+type PRPreview struct {
+	PullRequest PullRequest    `json:"pr"`
+	Reviewers   []ReviewerPick `json:"reviewers"`
+	Strategy    string         `json:"strategy"`
+}
+
+// Статусы PR
+const (
+	PRStatusDraft    = "DRAFT"
+	PRStatusOpen     = "OPEN"
+	PRStatusClosed   = "CLOSED"
+	PRStatusReopened = "REOPENED"
+	PRStatusMerged   = "MERGED"
+)
+
+// OpenStatuses - статусы, в которых PR ждет ревью
+var OpenStatuses = []string{PRStatusOpen, PRStatusReopened}
+
+// prTransitions - разрешенные переходы между статусами
+var prTransitions = map[string][]string{
+	PRStatusDraft:    {PRStatusOpen, PRStatusClosed},
+	PRStatusOpen:     {PRStatusClosed, PRStatusMerged},
+	PRStatusReopened: {PRStatusClosed, PRStatusMerged},
+	PRStatusClosed:   {PRStatusReopened},
+}
+
+func CanTransition(from string, to string) bool {
+	for _, status := range prTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOpen - PR ждет ревью (OPEN или REOPENED)
+func (pr *PullRequest) IsOpen() bool {
+	return pr.Status == PRStatusOpen || pr.Status == PRStatusReopened
+}
+
+// ReadyPRRequest - перевод черновика в OPEN. Repository и ChangedFiles,
+// как и при создании, нужны только для выбора владельцев кода
+type ReadyPRRequest struct {
+	PullRequestID string   `json:"pull_request_id"`
+	Repository    string   `json:"repository,omitempty"`
+	ChangedFiles  []string `json:"changed_files,omitempty"`
+}
+
+// UpdatePRRequest - частичное изменение метаданных PR: nil-поля не меняются.
+// Version - версия, которую видел клиент (или ETag из If-Match); если PR с тех пор
+// изменили, запрос отклоняется, чтобы не перетереть чужую правку
+type UpdatePRRequest struct {
+	PullRequestID   string    `json:"pull_request_id"`
+	Version         int       `json:"version"`
+	PullRequestName *string   `json:"pull_request_name,omitempty"`
+	Description     *string   `json:"description,omitempty"`
+	Labels          *[]string `json:"labels,omitempty"`
+	Actor           string    `json:"actor,omitempty"`
+}
+
+// SetDependenciesRequest - заменяет список родительских PR, от которых зависит PR
+type SetDependenciesRequest struct {
+	PullRequestID string   `json:"pull_request_id"`
+	DependsOn     []string `json:"depends_on"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+type UserAbsence struct {
+	AbsenceID int64     `json:"absence_id"`
+	UserID    string    `json:"user_id"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
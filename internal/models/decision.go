@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// Действие, при котором был выбран ревьюер
+const (
+	AssignmentActionCreate   = "create"
+	AssignmentActionReassign = "reassign"
+	AssignmentActionBackfill = "backfill"
+)
+
+// Причины, по которым кандидат не рассматривался
+const (
+	ExclusionAuthor          = "author"
+	ExclusionInactive        = "inactive"
+	ExclusionAlreadyAssigned = "already_assigned"
+	ExclusionAbsent          = "absent"
+	ExclusionOverCapacity    = "over_capacity"
+)
+
+// ConsideredCandidate - кандидат, которого упорядочивала стратегия.
+// Rank - место после стратегии и подъема по навыкам, с 1
+type ConsideredCandidate struct {
+	UserID       string `json:"user_id"`
+	OpenReviews  int    `json:"open_reviews"`
+	SkillMatches int    `json:"skill_matches"`
+	Rank         int    `json:"rank"`
+	Selected     bool   `json:"selected"`
+}
+
+type CandidateExclusion struct {
+	UserID string `json:"user_id"`
+	Reason string `json:"reason"`
+}
+
+// AssignmentDecision - почему ревьюер был выбран: на каком шаге (Rule - источник
+// из ReviewerSource*), какой стратегией, из кого выбирали и кого отбросили
+type AssignmentDecision struct {
+	DecisionID     int64                 `json:"decision_id"`
+	PullRequestID  string                `json:"pull_request_id"`
+	ReviewerID     string                `json:"reviewer_id"`
+	Action         string                `json:"action"`
+	Rule           string                `json:"rule"`
+	TeamName       string                `json:"team_name"`
+	Strategy       string                `json:"strategy"`
+	ReplacedUserID string                `json:"replaced_user_id,omitempty"`
+	Considered     []ConsideredCandidate `json:"considered"`
+	Excluded       []CandidateExclusion  `json:"excluded"`
+	CreatedAt      time.Time             `json:"created_at"`
+}
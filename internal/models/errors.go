@@ -1,6 +1,9 @@
 package models
 
-import "errors"
+import (
+	"errors"
+	"strings"
+)
 
 var (
 	ErrTeamExists  = errors.New("TEAM_EXISTS")
@@ -9,4 +12,85 @@ var (
 	ErrNotAssigned = errors.New("NOT_ASSIGNED")
 	ErrNoCandidate = errors.New("NO_CANDIDATE")
 	ErrNotFound    = errors.New("NOT_FOUND")
+
+	ErrUnknownStrategy = errors.New("UNKNOWN_STRATEGY")
+	ErrInvalidFallback = errors.New("INVALID_FALLBACK")
+	ErrInvalidOwners   = errors.New("INVALID_OWNERS")
+	ErrInvalidAbsence  = errors.New("INVALID_ABSENCE")
+
+	ErrInvalidTransition = errors.New("INVALID_TRANSITION")
+	ErrPRNotOpen         = errors.New("PR_NOT_OPEN")
+
+	ErrInvalidReviewState = errors.New("INVALID_REVIEW_STATE")
+	ErrInvalidMergePolicy = errors.New("INVALID_MERGE_POLICY")
+	ErrInvalidComment     = errors.New("INVALID_COMMENT")
+
+	ErrRepositoryExists = errors.New("REPOSITORY_EXISTS")
+	ErrInvalidPRRef     = errors.New("INVALID_PR_REF")
+
+	ErrVersionConflict = errors.New("VERSION_CONFLICT")
+
+	ErrInvalidDependency = errors.New("INVALID_DEPENDENCY")
+
+	ErrAlreadyMember = errors.New("ALREADY_MEMBER")
+	ErrNotMember     = errors.New("NOT_MEMBER")
+	ErrTeamLead      = errors.New("TEAM_LEAD")
+	ErrInvalidLead   = errors.New("INVALID_LEAD")
+
+	ErrTeamArchived      = errors.New("TEAM_ARCHIVED")
+	ErrInvalidMoveTarget = errors.New("INVALID_MOVE_TARGET")
+
+	ErrInvalidParent = errors.New("INVALID_PARENT")
+
+	ErrAmbiguousTeam   = errors.New("AMBIGUOUS_TEAM")
+	ErrAuthorNotInTeam = errors.New("AUTHOR_NOT_IN_TEAM")
+
+	// ErrNoTeam - у PR нет команды: она не выбрана при создании, у репозитория нет
+	// команды по умолчанию, а автор не состоит ни в одной команде
+	ErrNoTeam = errors.New("NO_TEAM")
+)
+
+// Условия политики merge
+const (
+	MergeConditionMinApprovals     = "min_approvals"
+	MergeConditionChangesRequested = "changes_requested"
+	MergeConditionLeadApproval     = "lead_approval"
+	MergeConditionResolvedThreads  = "resolved_threads"
 )
+
+type UnmetCondition struct {
+	Condition string `json:"condition"`
+	Message   string `json:"message"`
+}
+
+// MergeBlockedError - PR не проходит политику merge команды; проверять через errors.As
+type MergeBlockedError struct {
+	Unmet []UnmetCondition
+}
+
+// DependencyOpenError - у PR есть родительские PR, которые еще не влиты; проверять через errors.As
+type DependencyOpenError struct {
+	OpenParents []string
+}
+
+// OpenReviewsError - у пользователя есть OPEN ревью, а смена команды не подтверждена;
+// проверять через errors.As
+type OpenReviewsError struct {
+	PullRequestIDs []string
+}
+
+func (e *OpenReviewsError) Error() string {
+	return "OPEN_REVIEWS: " + strings.Join(e.PullRequestIDs, ", ")
+}
+
+func (e *DependencyOpenError) Error() string {
+	return "DEPENDENCY_OPEN: " + strings.Join(e.OpenParents, ", ")
+}
+
+func (e *MergeBlockedError) Error() string {
+	conditions := make([]string, len(e.Unmet))
+	for i, unmet := range e.Unmet {
+		conditions[i] = unmet.Condition
+	}
+	return "MERGE_BLOCKED: " + strings.Join(conditions, ", ")
+}
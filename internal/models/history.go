@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// События в истории PR
+const (
+	PREventReady    = "ready_for_review"
+	PREventClosed   = "closed"
+	PREventReopened = "reopened"
+	PREventMerged   = "merged"
+	PREventEdited   = "edited"
+
+	PREventReviewOverdue = "review_overdue"
+	PREventStale         = "stale"
+	PREventStaleCleared  = "stale_cleared"
+)
+
+// SystemPREvents - события, которые пишет сам сервис. Активностью в PR они не считаются
+var SystemPREvents = []string{PREventReviewOverdue, PREventStale, PREventStaleCleared}
+
+// PRHistoryEntry - запись в pull_request_history. В Details - подробности события,
+// например для merge с override - какие условия политики были проигнорированы
+type PRHistoryEntry struct {
+	HistoryID     int64          `json:"history_id"`
+	PullRequestID string         `json:"pull_request_id"`
+	Event         string         `json:"event"`
+	FromStatus    string         `json:"from_status"`
+	ToStatus      string         `json:"to_status"`
+	Actor         string         `json:"actor"`
+	Details       map[string]any `json:"details"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
+
+// MergePRRequest - Force разрешает merge в обход политики команды,
+// это фиксируется в истории PR вместе с Actor и Reason
+type MergePRRequest struct {
+	PullRequestID string `json:"pull_request_id"`
+	Force         bool   `json:"force,omitempty"`
+	Actor         string `json:"actor,omitempty"`
+	Reason        string `json:"reason,omitempty"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+const (
+	OwnershipScopeTeam       = "team"
+	OwnershipScopeRepository = "repository"
+)
+
+type OwnershipFile struct {
+	Scope     string    `json:"scope"`
+	ScopeRef  string    `json:"scope_ref"`
+	Content   string    `json:"content"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -1,13 +1,148 @@
 package models
 
+import "time"
+
+// User - пользователь. В списке участников команды TeamName - эта команда,
+// иначе - основная команда пользователя (а если ее нет - первая по времени вступления).
+// Teams (все команды пользователя) заполняется только при получении одного пользователя
 type User struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	TeamName string `json:"team_name"`
-	IsActive bool   `json:"is_active"`
+	UserID   string   `json:"user_id"`
+	Username string   `json:"username"`
+	TeamName string   `json:"team_name"`
+	IsActive bool     `json:"is_active"`
+	Skills   []string `json:"skills"`
+	Teams    []string `json:"teams,omitempty"`
+}
+
+// TeamMembership - членство пользователя в команде; основная команда у пользователя одна
+type TeamMembership struct {
+	UserID    string    `json:"user_id"`
+	TeamName  string    `json:"team_name"`
+	IsPrimary bool      `json:"is_primary"`
+	JoinedAt  time.Time `json:"joined_at"`
 }
 
+// Team - команда с метаданными. Команда может быть без участников.
+// Children заполняется только при запросе дерева подкоманд
 type Team struct {
+	TeamName     string     `json:"team_name"`
+	ParentName   string     `json:"parent_name,omitempty"`
+	Description  string     `json:"description"`
+	LeadUserID   string     `json:"lead_user_id,omitempty"`
+	ChatChannel  string     `json:"chat_channel"`
+	ReviewPolicy string     `json:"review_policy"`
+	ArchivedAt   *time.Time `json:"archived_at,omitempty"`
+	Members      []User     `json:"members"`
+	Children     []Team     `json:"children,omitempty"`
+}
+
+// TeamUpdate - изменение метаданных команды; nil-поля не меняются.
+// Пустой ParentName делает команду корневой
+type TeamUpdate struct {
+	TeamName     string  `json:"team_name"`
+	ParentName   *string `json:"parent_name,omitempty"`
+	Description  *string `json:"description,omitempty"`
+	LeadUserID   *string `json:"lead_user_id,omitempty"`
+	ChatChannel  *string `json:"chat_channel,omitempty"`
+	ReviewPolicy *string `json:"review_policy,omitempty"`
+}
+
+// AddMemberRequest - добавить в команду нового пользователя или существующего
+// (он остается и в своих командах). Primary - сделать команду основной; без него
+// команда становится основной, только если основной у пользователя еще нет
+type AddMemberRequest struct {
+	TeamName string `json:"team_name"`
+	User     User   `json:"user"`
+	Primary  bool   `json:"primary,omitempty"`
+}
+
+// RemoveMemberRequest - пользователь выходит из команды. Если у него есть OPEN ревью в PR
+// этой команды, нужно либо ReassignReviews (ревью переназначаются), либо Confirm (ревью остаются за ним)
+type RemoveMemberRequest struct {
+	TeamName        string `json:"team_name"`
+	UserID          string `json:"user_id"`
+	ReassignReviews bool   `json:"reassign_reviews,omitempty"`
+	Confirm         bool   `json:"confirm,omitempty"`
+}
+
+// MoveMemberRequest - перевести пользователя из FromTeam в ToTeam (основная команда
+// переходит вместе с ним). FromTeam можно не указывать, если команда у пользователя одна.
+// OPEN ревью - как в RemoveMemberRequest
+type MoveMemberRequest struct {
+	UserID          string `json:"user_id"`
+	FromTeam        string `json:"from_team,omitempty"`
+	ToTeam          string `json:"to_team"`
+	ReassignReviews bool   `json:"reassign_reviews,omitempty"`
+	Confirm         bool   `json:"confirm,omitempty"`
+}
+
+// SetPrimaryTeamRequest - сделать команду основной для пользователя; пустая - снять основную
+type SetPrimaryTeamRequest struct {
+	UserID   string `json:"user_id"`
 	TeamName string `json:"team_name"`
-	Members  []User `json:"members"` 
+}
+
+const (
+	StrategyFirstN       = "first_n"
+	StrategyRoundRobin   = "round_robin"
+	StrategyLeastLoaded  = "least_loaded"
+	StrategySeededRandom = "seeded_random"
+)
+
+type TeamSettings struct {
+	TeamName         string   `json:"team_name"`
+	ReviewerStrategy string   `json:"reviewer_strategy"`
+	ReviewerSeed     int64    `json:"reviewer_seed"`
+	ReviewerCount    int      `json:"reviewer_count"`
+	FallbackTeams    []string `json:"fallback_teams"`
+	RoundRobinCursor string   `json:"-"`
+
+	// ParentPool - если своей команды не хватает, ревьюеры добираются из пула
+	// родительских команд (родитель со всеми подкомандами), поднимаясь вверх по дереву
+	ParentPool bool `json:"parent_pool"`
+
+	// MaxOpenReviews - сколько открытых ревью может быть у ревьюера, прежде чем
+	// он перестанет выбираться (0 - без ограничения)
+	MaxOpenReviews int `json:"max_open_reviews"`
+
+	// Политика merge
+	MinApprovals            int    `json:"min_approvals"`
+	BlockOnChangesRequested bool   `json:"block_on_changes_requested"`
+	RequireLeadApproval     bool   `json:"require_lead_approval"`
+	LeadUserID              string `json:"lead_user_id"`
+	RequireResolvedThreads  bool   `json:"require_resolved_threads"`
+
+	// SLA ревью: первый ответ ревьюера в течение ReviewSLAHours рабочих часов (0 - без SLA).
+	// SLAReassign - просроченное ревью переназначается на другого ревьюера
+	ReviewSLAHours int  `json:"review_sla_hours"`
+	SLAReassign    bool `json:"sla_reassign"`
+
+	// Устаревшие PR: без активности StaleAfterDays дней PR помечается устаревшим,
+	// еще через StaleCloseAfterDays дней закрывается (0 - не закрывать)
+	StaleEnabled        bool `json:"stale_enabled"`
+	StaleAfterDays      int  `json:"stale_after_days"`
+	StaleCloseAfterDays int  `json:"stale_close_after_days"`
+}
+
+type TeamSettingsUpdate struct {
+	TeamName         string    `json:"team_name"`
+	ReviewerStrategy *string   `json:"reviewer_strategy,omitempty"`
+	ReviewerSeed     *int64    `json:"reviewer_seed,omitempty"`
+	ReviewerCount    *int      `json:"reviewer_count,omitempty"`
+	FallbackTeams    *[]string `json:"fallback_teams,omitempty"`
+	ParentPool       *bool     `json:"parent_pool,omitempty"`
+	MaxOpenReviews   *int      `json:"max_open_reviews,omitempty"`
+
+	MinApprovals            *int    `json:"min_approvals,omitempty"`
+	BlockOnChangesRequested *bool   `json:"block_on_changes_requested,omitempty"`
+	RequireLeadApproval     *bool   `json:"require_lead_approval,omitempty"`
+	LeadUserID              *string `json:"lead_user_id,omitempty"`
+	RequireResolvedThreads  *bool   `json:"require_resolved_threads,omitempty"`
+
+	ReviewSLAHours *int  `json:"review_sla_hours,omitempty"`
+	SLAReassign    *bool `json:"sla_reassign,omitempty"`
+
+	StaleEnabled        *bool `json:"stale_enabled,omitempty"`
+	StaleAfterDays      *int  `json:"stale_after_days,omitempty"`
+	StaleCloseAfterDays *int  `json:"stale_close_after_days,omitempty"`
 }
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"strconv"
+	"time"
+)
+
+// Repository - репозиторий, в котором живут PR. Пустые DefaultTeam, ReviewerStrategy
+// и nil ReviewerCount - берутся настройки команды автора PR
+type Repository struct {
+	Name             string    `json:"name"`
+	DefaultTeam      string    `json:"default_team,omitempty"`
+	ReviewerStrategy string    `json:"reviewer_strategy,omitempty"`
+	ReviewerCount    *int      `json:"reviewer_count,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// RepositorySettingsUpdate - пустая строка в DefaultTeam и ReviewerStrategy сбрасывает настройку
+type RepositorySettingsUpdate struct {
+	Name               string  `json:"name"`
+	DefaultTeam        *string `json:"default_team,omitempty"`
+	ReviewerStrategy   *string `json:"reviewer_strategy,omitempty"`
+	ReviewerCount      *int    `json:"reviewer_count,omitempty"`
+	ResetReviewerCount bool    `json:"reset_reviewer_count,omitempty"`
+}
+
+// RepositoryPRID - id PR внутри репозитория: номера PR уникальны только в своем репозитории
+func RepositoryPRID(repository string, number int) string {
+	return repository + "#" + strconv.Itoa(number)
+}
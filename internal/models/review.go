@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// Состояние ревью одного ревьюера
+const (
+	ReviewStatePending          = "PENDING"
+	ReviewStateApproved         = "APPROVED"
+	ReviewStateChangesRequested = "CHANGES_REQUESTED"
+	ReviewStateCommented        = "COMMENTED"
+)
+
+// PullRequestReview - последнее решение ревьюера по PR.
+// PENDING - ревью запрошено и еще не отправлено
+type PullRequestReview struct {
+	PullRequestID string     `json:"pull_request_id"`
+	ReviewerID    string     `json:"reviewer_id"`
+	State         string     `json:"state"`
+	Body          string     `json:"body"`
+	RequestedAt   time.Time  `json:"requested_at"`
+	SubmittedAt   *time.Time `json:"submitted_at,omitempty"`
+	OverdueAt     *time.Time `json:"overdue_at,omitempty"`
+}
+
+type SubmitReviewRequest struct {
+	PullRequestID string `json:"pull_request_id"`
+	ReviewerID    string `json:"reviewer_id"`
+	State         string `json:"state"`
+	Body          string `json:"body"`
+}
+
+// ReviewEscalation - ревью, просроченное по SLA команды. ReplacedBy - кому оно
+// переназначено (пусто, если команда не переназначает или замены не нашлось)
+type ReviewEscalation struct {
+	PullRequestID string    `json:"pull_request_id"`
+	ReviewerID    string    `json:"reviewer_id"`
+	RequestedAt   time.Time `json:"requested_at"`
+	Deadline      time.Time `json:"deadline"`
+	ReplacedBy    string    `json:"replaced_by,omitempty"`
+}
+
+func IsReviewDecision(state string) bool {
+	switch state {
+	case ReviewStateApproved, ReviewStateChangesRequested, ReviewStateCommented:
+		return true
+	}
+	return false
+}
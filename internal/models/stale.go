@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// StalePR - открытый PR команды со временем последней активности.
+// StaleAt - когда PR помечен устаревшим, CloseAt - когда он будет закрыт автоматически
+type StalePR struct {
+	PullRequestID   string     `json:"pull_request_id"`
+	PullRequestName string     `json:"pull_request_name"`
+	AuthorID        string     `json:"author_id"`
+	Status          string     `json:"status"`
+	Repository      string     `json:"repository,omitempty"`
+	TeamName        string     `json:"team_name"`
+	LastActivityAt  time.Time  `json:"last_activity_at"`
+	StaleAt         *time.Time `json:"stale_at,omitempty"`
+	CloseAt         *time.Time `json:"close_at,omitempty"`
+
+	StaleAfterDays      int `json:"-"`
+	StaleCloseAfterDays int `json:"-"`
+}
+
+// IsStale - PR помечен устаревшим и с тех пор в нем ничего не происходило
+func (pr *StalePR) IsStale() bool {
+	return pr.StaleAt != nil && !pr.LastActivityAt.After(*pr.StaleAt)
+}
@@ -23,9 +23,17 @@ type ServerStats struct {
 	TotalRequests int64   `json:"total_requests"`
 }
 
+// ReviewStats - состояние SLA ревью на момент последней проверки воркером
+type ReviewStats struct {
+	OverdueReviews int    `json:"overdue_reviews"`
+	Escalations    int64  `json:"escalations"`
+	LastSLACheck   string `json:"last_sla_check"`
+}
+
 type StatsResponse struct {
 	System    SystemStats `json:"system"`
 	Memory    MemoryStats `json:"memory"`
 	Server    ServerStats `json:"server"`
+	Reviews   ReviewStats `json:"reviews"`
 	Timestamp string      `json:"timestamp"`
 }
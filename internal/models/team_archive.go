@@ -0,0 +1,36 @@
+package models
+
+// Что делается с командой
+const (
+	TeamActionArchive = "archive"
+	TeamActionDelete  = "delete"
+)
+
+// ArchiveTeamRequest - архивировать или удалить команду. Участники переводятся
+// в MoveMembersTo или остаются без команды. Без Apply возвращается только план
+type ArchiveTeamRequest struct {
+	TeamName        string `json:"team_name"`
+	MoveMembersTo   string `json:"move_members_to,omitempty"`
+	ReassignReviews bool   `json:"reassign_reviews,omitempty"`
+	Apply           bool   `json:"apply,omitempty"`
+	Delete          bool   `json:"-"`
+}
+
+// MemberMove - куда уходит участник; пустой ToTeam - остается без команды
+type MemberMove struct {
+	UserID string `json:"user_id"`
+	ToTeam string `json:"to_team,omitempty"`
+}
+
+// TeamArchivePlan - план (Applied=false) или итог архивации/удаления команды
+type TeamArchivePlan struct {
+	TeamName          string               `json:"team_name"`
+	Action            string               `json:"action"`
+	Applied           bool                 `json:"applied"`
+	Members           []MemberMove         `json:"members"`
+	ReassignedReviews []ReviewReassignment `json:"reassigned_reviews"`
+	Repositories      []string             `json:"repositories"`
+	FallbackOf        []string             `json:"fallback_of"`
+	Children          []string             `json:"children"`
+	CodeOwnersRemoved bool                 `json:"codeowners_removed"`
+}
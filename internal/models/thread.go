@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// CommentThread - ветка обсуждения в PR: первый комментарий и ответы на него.
+// FilePath и Line необязательны - ветка без них относится ко всему PR
+type CommentThread struct {
+	ThreadID      int64      `json:"thread_id"`
+	PullRequestID string     `json:"pull_request_id"`
+	AuthorID      string     `json:"author_id"`
+	FilePath      string     `json:"file_path,omitempty"`
+	Line          *int       `json:"line,omitempty"`
+	Resolved      bool       `json:"resolved"`
+	ResolvedBy    string     `json:"resolved_by,omitempty"`
+	ResolvedAt    *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	Comments      []Comment  `json:"comments"`
+}
+
+type Comment struct {
+	CommentID int64     `json:"comment_id"`
+	ThreadID  int64     `json:"thread_id"`
+	AuthorID  string    `json:"author_id"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AddCommentRequest - без ThreadID открывает новую ветку в PR,
+// с ThreadID - ответ в существующую (FilePath и Line тогда не передаются)
+type AddCommentRequest struct {
+	PullRequestID string `json:"pull_request_id,omitempty"`
+	ThreadID      int64  `json:"thread_id,omitempty"`
+	AuthorID      string `json:"author_id"`
+	Body          string `json:"body"`
+	FilePath      string `json:"file_path,omitempty"`
+	Line          *int   `json:"line,omitempty"`
+}
+
+type ResolveThreadRequest struct {
+	ThreadID int64  `json:"thread_id"`
+	UserID   string `json:"user_id"`
+}
@@ -11,6 +11,10 @@ type StatService struct {
 	mu            sync.RWMutex
 	startTime     time.Time
 	totalRequests int64
+
+	overdueReviews int
+	escalations    int64
+	lastSLACheck   time.Time
 }
 
 func NewStatService() *StatService {
@@ -25,6 +29,16 @@ func (s *StatService) RegisterRequest() {
 	s.totalRequests++
 }
 
+// RecordSLACheck сохраняет итог прохода ReviewSLAWorker: сколько ревью сейчас просрочено
+// и сколько новых эскалаций сделано за проход
+func (s *StatService) RecordSLACheck(overdue int, escalated int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.overdueReviews = overdue
+	s.escalations += int64(escalated)
+	s.lastSLACheck = time.Now()
+}
+
 func (s *StatService) GetStats() models.StatsResponse {
 	var memStats runtime.MemStats
 	runtime.ReadMemStats(&memStats)
@@ -53,10 +67,22 @@ func (s *StatService) GetStats() models.StatsResponse {
 			StartTime:     s.startTime.Format(time.RFC3339),
 			TotalRequests: s.totalRequests,
 		},
+		Reviews:   s.reviewStats(),
 		Timestamp: time.Now().Format(time.RFC3339),
 	}
 }
 
+func (s *StatService) reviewStats() models.ReviewStats {
+	stats := models.ReviewStats{
+		OverdueReviews: s.overdueReviews,
+		Escalations:    s.escalations,
+	}
+	if !s.lastSLACheck.IsZero() {
+		stats.LastSLACheck = s.lastSLACheck.Format(time.RFC3339)
+	}
+	return stats
+}
+
 func (s *StatService) GetStartTime() time.Time {
 	return s.startTime
 }
@@ -0,0 +1,118 @@
+package services
+
+/*
+Разбор CODEOWNERS-файла:
+	1. Строка - "паттерн владелец1 владелец2 ..."; пустые строки и # - комментарии
+	2. Паттерны как в gitignore: * внутри сегмента, ** через сегменты,
+	   "/" в начале или середине привязывает к корню, "/" в конце - только каталог
+	3. Для пути побеждает последнее подходящее правило (как в GitHub)
+
+Владельцы: "@user_id" - пользователь, "@org/team" - команда (берется часть после "/")
+*/
+
+import (
+	"regexp"
+	"strings"
+	"subscription-budget/internal/models"
+)
+
+type CodeOwner struct {
+	UserID   string
+	TeamName string
+}
+
+type codeOwnersRule struct {
+	pattern *regexp.Regexp
+	owners  []CodeOwner
+}
+
+type CodeOwners struct {
+	rules []codeOwnersRule
+}
+
+func ParseCodeOwners(content string) (*CodeOwners, error) {
+	var result CodeOwners
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+
+		fields := strings.Fields(line)
+		pattern, err := compileOwnersPattern(fields[0])
+		if err != nil {
+			return nil, models.ErrInvalidOwners
+		}
+
+		rule := codeOwnersRule{pattern: pattern}
+		for _, token := range fields[1:] {
+			if !strings.HasPrefix(token, "@") || len(token) == 1 {
+				return nil, models.ErrInvalidOwners
+			}
+			token = strings.TrimPrefix(token, "@")
+
+			if i := strings.LastIndex(token, "/"); i >= 0 {
+				rule.owners = append(rule.owners, CodeOwner{TeamName: token[i+1:]})
+			} else {
+				rule.owners = append(rule.owners, CodeOwner{UserID: token})
+			}
+		}
+
+		result.rules = append(result.rules, rule)
+	}
+
+	return &result, nil
+}
+
+// OwnersFor возвращает владельцев пути по последнему подходящему правилу
+func (c *CodeOwners) OwnersFor(path string) []CodeOwner {
+	path = strings.TrimPrefix(path, "/")
+	for i := len(c.rules) - 1; i >= 0; i-- {
+		if c.rules[i].pattern.MatchString(path) {
+			return c.rules[i].owners
+		}
+	}
+	return nil
+}
+
+func compileOwnersPattern(pattern string) (*regexp.Regexp, error) {
+	anchored := strings.HasPrefix(pattern, "/") || strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.Trim(pattern, "/")
+
+	var expr strings.Builder
+	if anchored {
+		expr.WriteString("^")
+	} else {
+		expr.WriteString("^(?:.*/)?")
+	}
+
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			expr.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			expr.WriteString("[^/]*")
+		case pattern[i] == '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+
+	if dirOnly {
+		expr.WriteString("/.*$")
+	} else {
+		expr.WriteString("(?:/.*)?$")
+	}
+
+	return regexp.Compile(expr.String())
+}
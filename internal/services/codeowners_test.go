@@ -0,0 +1,148 @@
+package services
+
+/*
+Проверка разбора CODEOWNERS:
+	1. Паттерны: * внутри сегмента, **, привязка к корню через "/", "/" в конце - только каталог
+	2. Побеждает последнее подходящее правило
+	3. Владельцы: пользователи и команды, комментарии, ошибки в владельцах
+*/
+import (
+	"subscription-budget/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileOwnersPattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		match   []string
+		noMatch []string
+	}{
+		{
+			name:    "extension anywhere",
+			pattern: "*.go",
+			match:   []string{"main.go", "internal/services/codeowners.go"},
+			noMatch: []string{"main.js", "go.mod"},
+		},
+		{
+			name:    "star stays in one segment",
+			pattern: "/cmd/*.go",
+			match:   []string{"cmd/main.go"},
+			noMatch: []string{"cmd/tool/main.go", "src/cmd/main.go"},
+		},
+		{
+			name:    "anchored directory",
+			pattern: "/docs/",
+			match:   []string{"docs/readme.md", "docs/api/v1.md"},
+			noMatch: []string{"src/docs/readme.md", "docs", "docs.md"},
+		},
+		{
+			name:    "directory at any depth",
+			pattern: "docs/",
+			match:   []string{"docs/readme.md", "src/docs/readme.md"},
+			noMatch: []string{"docs", "src/docs.md"},
+		},
+		{
+			name:    "slash in the middle anchors to root",
+			pattern: "internal/storage",
+			match:   []string{"internal/storage/pool.go", "internal/storage"},
+			noMatch: []string{"pkg/internal/storage/pool.go"},
+		},
+		{
+			name:    "double star in the middle",
+			pattern: "apps/**/api",
+			match:   []string{"apps/api/handler.go", "apps/web/v1/api/handler.go"},
+			noMatch: []string{"lib/apps/web/api/handler.go", "apps/web/apis/handler.go"},
+		},
+		{
+			name:    "leading double star",
+			pattern: "**/logs",
+			match:   []string{"logs/app.log", "var/service/logs/app.log"},
+			noMatch: []string{"var/service/logsdir/app.log"},
+		},
+		{
+			name:    "trailing double star",
+			pattern: "/vendor/**",
+			match:   []string{"vendor/a.go", "vendor/github.com/x/y.go"},
+			noMatch: []string{"pkg/vendor/a.go"},
+		},
+		{
+			name:    "question mark is one character",
+			pattern: "v?.txt",
+			match:   []string{"v1.txt", "notes/v2.txt"},
+			noMatch: []string{"v10.txt", "v/.txt"},
+		},
+		{
+			name:    "dots are literal",
+			pattern: "go.mod",
+			match:   []string{"go.mod"},
+			noMatch: []string{"goXmod"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re, err := compileOwnersPattern(tt.pattern)
+			require.NoError(t, err)
+			for _, path := range tt.match {
+				assert.True(t, re.MatchString(path), "%q should match %q", tt.pattern, path)
+			}
+			for _, path := range tt.noMatch {
+				assert.False(t, re.MatchString(path), "%q should not match %q", tt.pattern, path)
+			}
+		})
+	}
+}
+
+func TestParseCodeOwners(t *testing.T) {
+	content := `
+# владельцы по умолчанию
+*                 @u-lead
+*.sql             @u-dba @acme/data   # миграции и запросы
+/internal/        @acme/backend
+/internal/storage/ @u-storage
+/docs/
+`
+	owners, err := ParseCodeOwners(content)
+	require.NoError(t, err)
+
+	tests := []struct {
+		path string
+		want []CodeOwner
+	}{
+		{path: "README.md", want: []CodeOwner{{UserID: "u-lead"}}},
+		{path: "migrations/00001.sql", want: []CodeOwner{{UserID: "u-dba"}, {TeamName: "data"}}},
+		{path: "internal/app/app.go", want: []CodeOwner{{TeamName: "backend"}}},
+		// последнее подходящее правило важнее более раннего
+		{path: "internal/storage/queries.sql", want: []CodeOwner{{UserID: "u-storage"}}},
+		{path: "/internal/storage/pool.go", want: []CodeOwner{{UserID: "u-storage"}}},
+		// правило без владельцев снимает владельцев
+		{path: "docs/guide.md", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, owners.OwnersFor(tt.path))
+		})
+	}
+}
+
+func TestParseCodeOwners_InvalidOwners(t *testing.T) {
+	for _, content := range []string{
+		"*.go u1",
+		"*.go @",
+		"*.go @u1 backend",
+	} {
+		_, err := ParseCodeOwners(content)
+		assert.ErrorIs(t, err, models.ErrInvalidOwners, content)
+	}
+}
+
+func TestParseCodeOwners_Empty(t *testing.T) {
+	owners, err := ParseCodeOwners("# только комментарии\n\n")
+	require.NoError(t, err)
+	assert.Nil(t, owners.OwnersFor("main.go"))
+}
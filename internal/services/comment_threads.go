@@ -0,0 +1,173 @@
+package services
+
+/*
+Ветки комментариев в PR:
+	1. AddComment - открыть ветку (без thread_id) или ответить в существующую
+	2. ResolveThread / UnresolveThread - пометить ветку решенной и открыть снова
+	3. GetCommentThreads - все ветки PR с комментариями
+
+Путь к файлу и строка задаются только при открытии ветки, строка - только вместе с путем.
+Комментировать и решать ветки можно в PR в любом статусе.
+Если у команды автора require_resolved_threads, нерешенные ветки блокируют merge (merge_policy.go).
+*/
+
+import (
+	"context"
+	"strings"
+	"subscription-budget/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (s *PullRequestService) AddComment(ctx context.Context, req models.AddCommentRequest) (*models.CommentThread, error) {
+	if err := validateComment(req); err != nil {
+		return nil, err
+	}
+
+	var result *models.CommentThread
+
+	err := s.executeWithRetry(ctx, func() error {
+		tx, err := s.PullRequestServ.PRBeginTx(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+
+		if _, err := s.userStorage.GetUserTx(ctx, tx, req.AuthorID); err != nil {
+			return models.ErrNotFound
+		}
+
+		threadID := req.ThreadID
+		if threadID == 0 {
+			if _, err := s.PullRequestServ.GetPRByIDTx(ctx, tx, req.PullRequestID); err != nil {
+				return models.ErrNotFound
+			}
+
+			threadID, err = s.PullRequestServ.CreateCommentThreadTx(ctx, tx, models.CommentThread{
+				PullRequestID: req.PullRequestID,
+				AuthorID:      req.AuthorID,
+				FilePath:      req.FilePath,
+				Line:          req.Line,
+			})
+			if err != nil {
+				return err
+			}
+		} else {
+			thread, err := s.PullRequestServ.GetCommentThreadTx(ctx, tx, threadID)
+			if err != nil {
+				return err
+			}
+			if req.PullRequestID != "" && req.PullRequestID != thread.PullRequestID {
+				return models.ErrNotFound
+			}
+		}
+
+		_, err = s.PullRequestServ.AddCommentTx(ctx, tx, models.Comment{
+			ThreadID: threadID,
+			AuthorID: req.AuthorID,
+			Body:     req.Body,
+		})
+		if err != nil {
+			return err
+		}
+
+		thread, err := s.PullRequestServ.GetCommentThreadTx(ctx, tx, threadID)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return err
+		}
+
+		result = thread
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func validateComment(req models.AddCommentRequest) error {
+	if strings.TrimSpace(req.Body) == "" {
+		return models.ErrInvalidComment
+	}
+
+	if req.ThreadID != 0 {
+		if req.FilePath != "" || req.Line != nil {
+			return models.ErrInvalidComment
+		}
+		return nil
+	}
+
+	if req.Line != nil && (req.FilePath == "" || *req.Line <= 0) {
+		return models.ErrInvalidComment
+	}
+
+	return nil
+}
+
+func (s *PullRequestService) ResolveThread(ctx context.Context, req models.ResolveThreadRequest) (*models.CommentThread, error) {
+	return s.setThreadResolved(ctx, req, true)
+}
+
+func (s *PullRequestService) UnresolveThread(ctx context.Context, req models.ResolveThreadRequest) (*models.CommentThread, error) {
+	return s.setThreadResolved(ctx, req, false)
+}
+
+func (s *PullRequestService) setThreadResolved(ctx context.Context, req models.ResolveThreadRequest, resolved bool) (*models.CommentThread, error) {
+	var result *models.CommentThread
+
+	err := s.executeWithRetry(ctx, func() error {
+		tx, err := s.PullRequestServ.PRBeginTx(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+
+		if _, err := s.userStorage.GetUserTx(ctx, tx, req.UserID); err != nil {
+			return models.ErrNotFound
+		}
+
+		if err := s.PullRequestServ.SetThreadResolvedTx(ctx, tx, req.ThreadID, resolved, req.UserID); err != nil {
+			return err
+		}
+
+		thread, err := s.PullRequestServ.GetCommentThreadTx(ctx, tx, req.ThreadID)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return err
+		}
+
+		result = thread
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *PullRequestService) GetCommentThreads(ctx context.Context, prID string) ([]models.CommentThread, error) {
+	if _, err := s.PullRequestServ.GetPRByIDTx(ctx, nil, prID); err != nil {
+		return nil, err
+	}
+
+	return s.PullRequestServ.GetCommentThreadsTx(ctx, nil, prID)
+}
+
+// unresolvedThreads - число нерешенных веток, если политика команды их учитывает
+func (s *PullRequestService) unresolvedThreads(ctx context.Context, tx pgx.Tx, settings *models.TeamSettings, prID string) (int, error) {
+	if !settings.RequireResolvedThreads {
+		return 0, nil
+	}
+	return s.PullRequestServ.CountUnresolvedThreadsTx(ctx, tx, prID)
+}
@@ -0,0 +1,30 @@
+package services
+
+/*
+Общие помощники для сервисов команд, юзеров и PR:
+	1. contains - есть ли строка в списке
+	2. normalizeTags - навыки юзеров и метки PR в одном виде
+*/
+
+import "strings"
+
+func contains(slice []string, item string) bool {
+	for _, s := range slice {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeTags приводит навыки и метки к нижнему регистру и убирает дубли
+func normalizeTags(tags []string) []string {
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !contains(result, tag) {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
@@ -0,0 +1,69 @@
+package services
+
+/*
+Политика merge команды автора PR (teams):
+	1. min_approvals - сколько ревьюеров должны быть в APPROVED
+	2. block_on_changes_requested - ни у кого из ревьюеров не должно быть CHANGES_REQUESTED
+	3. require_lead_approval - нужен APPROVED от лида команды (lead_user_id).
+	   Для PR самого лида условие не проверяется - одобрить свой PR он не может.
+	   Чтобы лиду было что одобрять, он назначается ревьюером при создании PR
+	4. require_resolved_threads - все ветки комментариев в PR решены
+
+Несоблюденные условия возвращаются списком в models.MergeBlockedError.
+Merge с force проходит в обход политики, проигнорированные условия пишутся в историю PR.
+*/
+
+import (
+	"fmt"
+	"strings"
+	"subscription-budget/internal/models"
+)
+
+func checkMergePolicy(settings *models.TeamSettings, pr *models.PullRequest, reviews []models.PullRequestReview, unresolvedThreads int) []models.UnmetCondition {
+	unmet := []models.UnmetCondition{}
+
+	approvals := 0
+	leadApproved := false
+	var changesRequested []string
+	for _, review := range reviews {
+		switch review.State {
+		case models.ReviewStateApproved:
+			approvals++
+			if review.ReviewerID == settings.LeadUserID {
+				leadApproved = true
+			}
+		case models.ReviewStateChangesRequested:
+			changesRequested = append(changesRequested, review.ReviewerID)
+		}
+	}
+
+	if approvals < settings.MinApprovals {
+		unmet = append(unmet, models.UnmetCondition{
+			Condition: models.MergeConditionMinApprovals,
+			Message:   fmt.Sprintf("%d of %d required approvals", approvals, settings.MinApprovals),
+		})
+	}
+
+	if settings.BlockOnChangesRequested && len(changesRequested) > 0 {
+		unmet = append(unmet, models.UnmetCondition{
+			Condition: models.MergeConditionChangesRequested,
+			Message:   "changes requested by " + strings.Join(changesRequested, ", "),
+		})
+	}
+
+	if settings.RequireLeadApproval && pr.AuthorID != settings.LeadUserID && !leadApproved {
+		unmet = append(unmet, models.UnmetCondition{
+			Condition: models.MergeConditionLeadApproval,
+			Message:   "approval from team lead " + settings.LeadUserID + " is required",
+		})
+	}
+
+	if settings.RequireResolvedThreads && unresolvedThreads > 0 {
+		unmet = append(unmet, models.UnmetCondition{
+			Condition: models.MergeConditionResolvedThreads,
+			Message:   fmt.Sprintf("%d unresolved comment threads", unresolvedThreads),
+		})
+	}
+
+	return unmet
+}
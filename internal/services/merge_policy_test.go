@@ -0,0 +1,131 @@
+package services
+
+/*
+Проверка политики merge (checkMergePolicy):
+	1. Минимум одобрений
+	2. Запрошенные изменения
+	3. Одобрение лида, PR самого лида
+	4. Нерешенные ветки комментариев
+*/
+import (
+	"subscription-budget/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckMergePolicy(t *testing.T) {
+	approved := func(reviewerID string) models.PullRequestReview {
+		return models.PullRequestReview{ReviewerID: reviewerID, State: models.ReviewStateApproved}
+	}
+	changesRequested := func(reviewerID string) models.PullRequestReview {
+		return models.PullRequestReview{ReviewerID: reviewerID, State: models.ReviewStateChangesRequested}
+	}
+	pending := func(reviewerID string) models.PullRequestReview {
+		return models.PullRequestReview{ReviewerID: reviewerID, State: models.ReviewStatePending}
+	}
+
+	tests := []struct {
+		name       string
+		settings   models.TeamSettings
+		authorID   string
+		reviews    []models.PullRequestReview
+		unresolved int
+		want       []string
+	}{
+		{
+			name:     "empty policy allows merge",
+			authorID: "author",
+			reviews:  []models.PullRequestReview{pending("u1"), changesRequested("u2")},
+			want:     []string{},
+		},
+		{
+			name:     "not enough approvals",
+			settings: models.TeamSettings{MinApprovals: 2},
+			authorID: "author",
+			reviews:  []models.PullRequestReview{approved("u1"), pending("u2")},
+			want:     []string{models.MergeConditionMinApprovals},
+		},
+		{
+			name:     "enough approvals",
+			settings: models.TeamSettings{MinApprovals: 2},
+			authorID: "author",
+			reviews:  []models.PullRequestReview{approved("u1"), approved("u2")},
+			want:     []string{},
+		},
+		{
+			name:     "changes requested blocks when enabled",
+			settings: models.TeamSettings{BlockOnChangesRequested: true},
+			authorID: "author",
+			reviews:  []models.PullRequestReview{approved("u1"), changesRequested("u2")},
+			want:     []string{models.MergeConditionChangesRequested},
+		},
+		{
+			name:     "lead approval missing",
+			settings: models.TeamSettings{RequireLeadApproval: true, LeadUserID: "lead"},
+			authorID: "author",
+			reviews:  []models.PullRequestReview{approved("u1"), pending("lead")},
+			want:     []string{models.MergeConditionLeadApproval},
+		},
+		{
+			name:     "lead approved",
+			settings: models.TeamSettings{RequireLeadApproval: true, LeadUserID: "lead", MinApprovals: 1},
+			authorID: "author",
+			reviews:  []models.PullRequestReview{approved("lead")},
+			want:     []string{},
+		},
+		{
+			name:     "author is lead",
+			settings: models.TeamSettings{RequireLeadApproval: true, LeadUserID: "lead"},
+			authorID: "lead",
+			reviews:  []models.PullRequestReview{pending("u1")},
+			want:     []string{},
+		},
+		{
+			name:       "unresolved threads",
+			settings:   models.TeamSettings{RequireResolvedThreads: true},
+			authorID:   "author",
+			unresolved: 2,
+			want:       []string{models.MergeConditionResolvedThreads},
+		},
+		{
+			name:       "unresolved threads ignored when disabled",
+			authorID:   "author",
+			unresolved: 2,
+			want:       []string{},
+		},
+		{
+			name: "all conditions unmet",
+			settings: models.TeamSettings{
+				MinApprovals:            1,
+				BlockOnChangesRequested: true,
+				RequireLeadApproval:     true,
+				LeadUserID:              "lead",
+				RequireResolvedThreads:  true,
+			},
+			authorID:   "author",
+			reviews:    []models.PullRequestReview{changesRequested("lead")},
+			unresolved: 1,
+			want: []string{
+				models.MergeConditionMinApprovals,
+				models.MergeConditionChangesRequested,
+				models.MergeConditionLeadApproval,
+				models.MergeConditionResolvedThreads,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pr := &models.PullRequest{PullRequestID: "PR-1", AuthorID: tt.authorID}
+			unmet := checkMergePolicy(&tt.settings, pr, tt.reviews, tt.unresolved)
+
+			conditions := make([]string, len(unmet))
+			for i, condition := range unmet {
+				conditions[i] = condition.Condition
+				assert.NotEmpty(t, condition.Message)
+			}
+			assert.Equal(t, tt.want, conditions)
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package services
+
+/*
+Функции:
+	1. Загрузка CODEOWNERS-файла для команды или репозитория
+	2. Получение загруженного файла
+
+Файл разбирается при загрузке, чтобы битый файл не попал в базу
+и не сломал назначение ревьюеров при создании PR
+*/
+
+import (
+	"context"
+	"subscription-budget/internal/models"
+	"subscription-budget/internal/storage"
+	"time"
+)
+
+type OwnershipService struct {
+	ownershipStorage storage.OwnershipStorage
+	teamStorage      storage.TeamStorage
+}
+
+func NewOwnershipService(ownershipStorage storage.OwnershipStorage, teamStorage storage.TeamStorage) *OwnershipService {
+	return &OwnershipService{
+		ownershipStorage: ownershipStorage,
+		teamStorage:      teamStorage,
+	}
+}
+
+func (s *OwnershipService) executeWithRetry(ctx context.Context, operation func() error) error {
+	maxRetries := 3
+	var lastErr error
+
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(time.Duration(attempt) * 100 * time.Millisecond):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		err := operation()
+		if err == nil {
+			return nil
+		}
+
+		lastErr = err
+	}
+
+	return lastErr
+}
+
+func (s *OwnershipService) UploadOwnershipFile(ctx context.Context, file models.OwnershipFile) (*models.OwnershipFile, error) {
+	if file.Scope != models.OwnershipScopeTeam && file.Scope != models.OwnershipScopeRepository {
+		return nil, models.ErrInvalidOwners
+	}
+
+	if _, err := ParseCodeOwners(file.Content); err != nil {
+		return nil, err
+	}
+
+	var result *models.OwnershipFile
+
+	err := s.executeWithRetry(ctx, func() error {
+		tx, err := s.ownershipStorage.OwnershipBeginTx(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+
+		if file.Scope == models.OwnershipScopeTeam {
+			if _, err := s.teamStorage.GetTeamSettingsTx(ctx, tx, file.ScopeRef); err != nil {
+				return err
+			}
+		}
+
+		err = s.ownershipStorage.UpsertOwnershipFileTx(ctx, tx, file)
+		if err != nil {
+			return err
+		}
+
+		saved, err := s.ownershipStorage.GetOwnershipFileTx(ctx, tx, file.Scope, file.ScopeRef)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return err
+		}
+
+		result = saved
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *OwnershipService) GetOwnershipFile(ctx context.Context, scope string, scopeRef string) (*models.OwnershipFile, error) {
+	var result *models.OwnershipFile
+
+	err := s.executeWithRetry(ctx, func() error {
+		file, err := s.ownershipStorage.GetOwnershipFileTx(ctx, nil, scope, scopeRef)
+		if err != nil {
+			return err
+		}
+
+		result = file
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
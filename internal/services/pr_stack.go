@@ -0,0 +1,181 @@
+package services
+
+/*
+Стеки PR (stacked diffs):
+	1. SetDependencies - задать родительские PR, от которых зависит PR
+	   (их же можно передать в depends_on при создании)
+	2. GetPRStack - весь стек PR в порядке merge: каждый PR идет после всех своих родителей
+	3. MergePR не вливает PR, пока хоть один родитель в DRAFT, OPEN или REOPENED
+	   (закрытый без merge родитель не блокирует). force это не обходит
+
+Циклы запрещены: после записи зависимостей стек сортируется, и при цикле
+транзакция откатывается с ErrInvalidDependency.
+*/
+
+import (
+	"context"
+	"subscription-budget/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (s *PullRequestService) SetDependencies(ctx context.Context, req models.SetDependenciesRequest) (*models.PullRequest, error) {
+	var result *models.PullRequest
+
+	err := s.executeWithRetry(ctx, func() error {
+		tx, err := s.PullRequestServ.PRBeginTx(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+
+		pr, err := s.PullRequestServ.GetPRByIDTx(ctx, tx, req.PullRequestID)
+		if err != nil {
+			return err
+		}
+
+		if pr.Status == models.PRStatusMerged {
+			return models.ErrPRMerged
+		}
+
+		if err := s.setDependenciesTx(ctx, tx, pr.PullRequestID, req.DependsOn); err != nil {
+			return err
+		}
+
+		pr, err = s.PullRequestServ.GetPRByIDTx(ctx, tx, pr.PullRequestID)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return err
+		}
+
+		result = pr
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// setDependenciesTx проверяет родителей, сохраняет их и убеждается, что стек остался без циклов
+func (s *PullRequestService) setDependenciesTx(ctx context.Context, tx pgx.Tx, prID string, dependsOn []string) error {
+	parents := []string{}
+	for _, parentID := range dependsOn {
+		if parentID == "" || parentID == prID {
+			return models.ErrInvalidDependency
+		}
+		if contains(parents, parentID) {
+			continue
+		}
+		if _, err := s.PullRequestServ.GetPRByIDTx(ctx, tx, parentID); err != nil {
+			return err
+		}
+		parents = append(parents, parentID)
+	}
+
+	if err := s.PullRequestServ.SetPRDependenciesTx(ctx, tx, prID, parents); err != nil {
+		return err
+	}
+
+	stack, err := s.PullRequestServ.GetPRStackTx(ctx, tx, prID)
+	if err != nil {
+		return err
+	}
+
+	_, err = mergeOrder(stack)
+	return err
+}
+
+// createDependenciesTx - зависимости из запроса на создание PR
+func (s *PullRequestService) createDependenciesTx(ctx context.Context, tx pgx.Tx, pr *models.PullRequest, dependsOn []string) error {
+	if len(dependsOn) == 0 {
+		return nil
+	}
+
+	if err := s.setDependenciesTx(ctx, tx, pr.PullRequestID, dependsOn); err != nil {
+		return err
+	}
+
+	saved, err := s.PullRequestServ.GetPRByIDTx(ctx, tx, pr.PullRequestID)
+	if err != nil {
+		return err
+	}
+	pr.DependsOn = saved.DependsOn
+	return nil
+}
+
+func (s *PullRequestService) GetPRStack(ctx context.Context, prID string) ([]models.PullRequest, error) {
+	if _, err := s.PullRequestServ.GetPRByIDTx(ctx, nil, prID); err != nil {
+		return nil, err
+	}
+
+	stack, err := s.PullRequestServ.GetPRStackTx(ctx, nil, prID)
+	if err != nil {
+		return nil, err
+	}
+
+	return mergeOrder(stack)
+}
+
+// openParentsTx - родители PR, которые еще могут быть влиты (DRAFT, OPEN, REOPENED)
+func (s *PullRequestService) openParentsTx(ctx context.Context, tx pgx.Tx, pr *models.PullRequest) ([]string, error) {
+	open := []string{}
+	for _, parentID := range pr.DependsOn {
+		parent, err := s.PullRequestServ.GetPRByIDTx(ctx, tx, parentID)
+		if err != nil {
+			return nil, err
+		}
+		if parent.Status != models.PRStatusMerged && parent.Status != models.PRStatusClosed {
+			open = append(open, parentID)
+		}
+	}
+	return open, nil
+}
+
+// mergeOrder сортирует стек так, чтобы родители шли раньше детей.
+// Среди готовых к merge сохраняется исходный порядок (по created_at)
+func mergeOrder(stack []models.PullRequest) ([]models.PullRequest, error) {
+	waiting := make(map[string]int, len(stack))
+	children := make(map[string][]string, len(stack))
+	byID := make(map[string]models.PullRequest, len(stack))
+	for _, pr := range stack {
+		byID[pr.PullRequestID] = pr
+	}
+	for _, pr := range stack {
+		for _, parentID := range pr.DependsOn {
+			if _, ok := byID[parentID]; !ok {
+				continue
+			}
+			waiting[pr.PullRequestID]++
+			children[parentID] = append(children[parentID], pr.PullRequestID)
+		}
+	}
+
+	ordered := make([]models.PullRequest, 0, len(stack))
+	done := make(map[string]bool, len(stack))
+	for len(ordered) < len(stack) {
+		progressed := false
+		for _, pr := range stack {
+			if done[pr.PullRequestID] || waiting[pr.PullRequestID] > 0 {
+				continue
+			}
+			done[pr.PullRequestID] = true
+			ordered = append(ordered, pr)
+			for _, childID := range children[pr.PullRequestID] {
+				waiting[childID]--
+			}
+			progressed = true
+			break
+		}
+		if !progressed {
+			return nil, models.ErrInvalidDependency
+		}
+	}
+
+	return ordered, nil
+}
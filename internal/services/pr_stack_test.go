@@ -0,0 +1,80 @@
+package services
+
+/*
+Проверка порядка merge стека PR (mergeOrder):
+	1. Цепочка, ромб, независимые PR, родители вне стека
+	2. Цикл - ErrInvalidDependency
+*/
+import (
+	"subscription-budget/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeOrder(t *testing.T) {
+	pr := func(id string, dependsOn ...string) models.PullRequest {
+		return models.PullRequest{PullRequestID: id, DependsOn: dependsOn}
+	}
+
+	tests := []struct {
+		name    string
+		stack   []models.PullRequest
+		want    []string
+		wantErr error
+	}{
+		{
+			name:  "empty stack",
+			stack: []models.PullRequest{},
+			want:  []string{},
+		},
+		{
+			name:  "chain in reverse order",
+			stack: []models.PullRequest{pr("C", "B"), pr("B", "A"), pr("A")},
+			want:  []string{"A", "B", "C"},
+		},
+		{
+			name:  "diamond keeps original order among ready PRs",
+			stack: []models.PullRequest{pr("D", "B", "C"), pr("C", "A"), pr("B", "A"), pr("A")},
+			want:  []string{"A", "C", "B", "D"},
+		},
+		{
+			name:  "independent PRs keep original order",
+			stack: []models.PullRequest{pr("X"), pr("Y"), pr("Z")},
+			want:  []string{"X", "Y", "Z"},
+		},
+		{
+			name:  "parents outside the stack are ignored",
+			stack: []models.PullRequest{pr("B", "A", "MERGED-1"), pr("A", "MERGED-2")},
+			want:  []string{"A", "B"},
+		},
+		{
+			name:    "cycle",
+			stack:   []models.PullRequest{pr("A", "B"), pr("B", "A")},
+			wantErr: models.ErrInvalidDependency,
+		},
+		{
+			name:    "cycle behind a ready PR",
+			stack:   []models.PullRequest{pr("A"), pr("B", "A", "D"), pr("C", "B"), pr("D", "C")},
+			wantErr: models.ErrInvalidDependency,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ordered, err := mergeOrder(tt.stack)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			ids := make([]string, len(ordered))
+			for i, pr := range ordered {
+				ids[i] = pr.PullRequestID
+			}
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+}
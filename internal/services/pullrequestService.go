@@ -2,10 +2,34 @@ package services
 
 /*
 Функции:
-	1. Создание pr
+	1. Создание pr (сразу OPEN или черновиком - DRAFT, без ревьюеров)
 	2. Merge
 	3. Переназначение пользоватля
+	   (в том числе снятие всех OPEN ревью с деактивированного пользователя)
 	4. По пользователю найти Ревью
+	5. Предпросмотр назначения (dry-run): тот же путь, что и создание,
+	   но транзакция откатывается, а к ревьюерам добавляется причина выбора
+	6. Получение PR, по запросу - вместе с сохраненными причинами выбора
+	   каждого ревьюера (pr_assignment_decisions)
+	7. Жизненный цикл: DRAFT -> OPEN (ready, тут назначаются ревьюеры),
+	   закрытие без merge (DRAFT/OPEN/REOPENED -> CLOSED) и переоткрытие
+	   (CLOSED -> REOPENED). Merge - только из OPEN и REOPENED.
+	   Все переходы - в models.CanTransition
+	8. Ревью: решение ревьюера (APPROVED / CHANGES_REQUESTED / COMMENTED)
+	   и повторный запрос ревью (сброс в PENDING, например после новых коммитов)
+	9. Merge проверяется по политике команды PR (см. merge_policy.go).
+	   Смены статуса пишутся в историю PR (pull_request_history)
+	10. Репозитории: PR в репозитории создается по номеру, id - "repository#number".
+	    Команда PR - команда по умолчанию репозитория, иначе команда автора;
+	    стратегия и число ревьюеров репозитория перекрывают настройки команды
+	11. Изменение метаданных (название, описание, метки) с оптимистической
+	    блокировкой по version; каждое изменение пишется в историю PR
+	12. Зависимости между PR (стеки) и порядок merge - см. pr_stack.go
+
+Число ревьюеров берется из teams.reviewer_count (или repositories.reviewer_count),
+либо из reviewer_count в запросе на создание PR. Если кандидатов не хватило, PR создается с
+меньшим числом ревьюеров, а целевое число сохраняется в target_reviewers.
+Как выбираются сами ревьюеры - см. reviewer_selection.go
 
 Основная сложность в написании сервиса была связана с возможным рейс кондишн.
 Было исправлено за счет транзакций
@@ -22,20 +46,29 @@ import (
 )
 
 type PullRequestService struct {
-	PullRequestServ storage.PullReqStorage
-	userStorage     storage.UserStorage
-	teamStorage     storage.TeamStorage
+	PullRequestServ  storage.PullReqStorage
+	userStorage      storage.UserStorage
+	teamStorage      storage.TeamStorage
+	ownershipStorage storage.OwnershipStorage
+	repoStorage      storage.RepositoryStorage
+	strategies       *StrategyRegistry
 }
 
 func NewPullRequestService(
 	PullRequestServ storage.PullReqStorage,
 	userStorage storage.UserStorage,
 	teamStorage storage.TeamStorage,
+	ownershipStorage storage.OwnershipStorage,
+	repoStorage storage.RepositoryStorage,
+	strategies *StrategyRegistry,
 ) *PullRequestService {
 	return &PullRequestService{
-		PullRequestServ: PullRequestServ,
-		userStorage:     userStorage,
-		teamStorage:     teamStorage,
+		PullRequestServ:  PullRequestServ,
+		userStorage:      userStorage,
+		teamStorage:      teamStorage,
+		ownershipStorage: ownershipStorage,
+		repoStorage:      repoStorage,
+		strategies:       strategies,
 	}
 }
 
@@ -73,31 +106,307 @@ func (s *PullRequestService) CreatePR(ctx context.Context, req models.CreatePRRe
 		}
 		defer tx.Rollback(ctx)
 
-		author, err := s.userStorage.GetUserTx(ctx, tx, req.AuthorID)
+		pr, _, err := s.createPRTx(ctx, tx, req)
 		if err != nil {
-			return models.ErrNotFound
+			return err
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return err
 		}
 
-		team, err := s.teamStorage.GetTeamInfoTx(ctx, tx, author.TeamName)
+		result = pr
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// PreviewPR выбирает ревьюеров так же, как CreatePR, но ничего не сохраняет:
+// транзакция всегда откатывается (вместе с курсором round_robin)
+func (s *PullRequestService) PreviewPR(ctx context.Context, req models.CreatePRRequest) (*models.PRPreview, error) {
+	var result *models.PRPreview
+
+	err := s.executeWithRetry(ctx, func() error {
+		tx, err := s.PullRequestServ.PRBeginTx(ctx)
 		if err != nil {
-			return models.ErrNotFound
+			return err
 		}
+		defer tx.Rollback(ctx)
 
-		reviewers := s.findReviewersFromTeam(team, req.AuthorID)
+		pr, picks, err := s.createPRTx(ctx, tx, req)
+		if err != nil {
+			return err
+		}
 
-		pr := models.PullRequest{
-			PullRequestID:     req.PullRequestID,
-			PullRequestName:   req.PullRequestName,
-			AuthorID:          req.AuthorID,
-			Status:            "OPEN",
-			AssignedReviewers: reviewers,
+		for i := range picks {
+			user, err := s.userStorage.GetUserTx(ctx, tx, picks[i].UserID)
+			if err != nil {
+				return err
+			}
+			picks[i].TeamName = user.TeamName
+			picks[i].MatchedLabels = []string{}
+			for _, label := range pr.Labels {
+				if contains(user.Skills, label) {
+					picks[i].MatchedLabels = append(picks[i].MatchedLabels, label)
+				}
+			}
+		}
+
+		_, settings, err := s.prTeamTx(ctx, tx, pr.AuthorID, pr.Repository, pr.TeamName)
+		if err != nil {
+			return err
+		}
+
+		result = &models.PRPreview{
+			PullRequest: *pr,
+			Reviewers:   picks,
+			Strategy:    settings.ReviewerStrategy,
 		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// createPRTx выбирает ревьюеров и создает PR в переданной транзакции.
+// Вместе с PR возвращает, откуда взят каждый ревьюер
+func (s *PullRequestService) createPRTx(ctx context.Context, tx pgx.Tx, req models.CreatePRRequest) (*models.PullRequest, []models.ReviewerPick, error) {
+	prID, err := newPRID(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.PullRequestID = prID
+
+	repository, err := s.registeredRepositoryTx(ctx, tx, req.Repository)
+	if err != nil {
+		return nil, nil, err
+	}
+	if req.Number != 0 && repository == "" {
+		return nil, nil, models.ErrNotFound
+	}
+
+	team, settings, err := s.prTeamTx(ctx, tx, req.AuthorID, req.Repository, req.TeamName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Команду PR можно выбрать только из своих: от нее зависят ревьюеры и политика merge
+	if req.TeamName != "" {
+		memberships, err := s.teamStorage.GetMemberTeamsTx(ctx, tx, req.AuthorID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !isMemberOf(memberships, req.TeamName) {
+			return nil, nil, models.ErrAuthorNotInTeam
+		}
+		if team.ArchivedAt != nil {
+			return nil, nil, models.ErrTeamArchived
+		}
+	}
+
+	target := settings.ReviewerCount
+	if req.ReviewerCount != nil {
+		target = *req.ReviewerCount
+	}
+
+	pr := models.PullRequest{
+		PullRequestID:   req.PullRequestID,
+		PullRequestName: req.PullRequestName,
+		Description:     req.Description,
+		AuthorID:        req.AuthorID,
+		Status:          models.PRStatusOpen,
+		TargetReviewers: target,
+		Labels:          normalizeTags(req.Labels),
+		Repository:      repository,
+		Number:          req.Number,
+		TeamName:        req.TeamName,
+		DependsOn:       []string{},
+	}
+
+	// Черновик создается без ревьюеров, они выбираются при переводе в OPEN
+	if req.Draft {
+		pr.Status = models.PRStatusDraft
 
 		err = s.PullRequestServ.CreatePRTx(ctx, tx, pr)
 		if err != nil {
 			if isUniqueConstraintError(err) {
-				return models.ErrPRExists
+				return nil, nil, models.ErrPRExists
 			}
+			return nil, nil, err
+		}
+		if err := s.createDependenciesTx(ctx, tx, &pr, req.DependsOn); err != nil {
+			return nil, nil, err
+		}
+		return &pr, []models.ReviewerPick{}, nil
+	}
+
+	selected, err := s.selectInitialReviewers(ctx, tx, team, settings, req, pr.Labels, target)
+	if err != nil {
+		return nil, nil, err
+	}
+	pr.AssignedReviewers = selected.reviewers
+	pr.CrossTeamReviewers = selected.crossTeam
+
+	err = s.PullRequestServ.CreatePRTx(ctx, tx, pr)
+	if err != nil {
+		if isUniqueConstraintError(err) {
+			return nil, nil, models.ErrPRExists
+		}
+		return nil, nil, err
+	}
+
+	if err := s.createDependenciesTx(ctx, tx, &pr, req.DependsOn); err != nil {
+		return nil, nil, err
+	}
+
+	decisions := selected.trace.decisions(pr.PullRequestID, models.AssignmentActionCreate, "", selected.picks)
+	if err := s.PullRequestServ.CreateAssignmentDecisionsTx(ctx, tx, decisions); err != nil {
+		return nil, nil, err
+	}
+
+	return &pr, selected.picks, nil
+}
+
+// ReadyForReview переводит черновик в OPEN и назначает ревьюеров тем же путем, что и CreatePR
+func (s *PullRequestService) ReadyForReview(ctx context.Context, req models.ReadyPRRequest) (*models.PullRequest, error) {
+	var result *models.PullRequest
+
+	err := s.executeWithRetry(ctx, func() error {
+		tx, err := s.PullRequestServ.PRBeginTx(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+
+		pr, err := s.PullRequestServ.GetPRByIDTx(ctx, tx, req.PullRequestID)
+		if err != nil {
+			return models.ErrNotFound
+		}
+
+		if !models.CanTransition(pr.Status, models.PRStatusOpen) {
+			return models.ErrInvalidTransition
+		}
+
+		team, settings, err := s.prTeamTx(ctx, tx, pr.AuthorID, pr.Repository, pr.TeamName)
+		if err != nil {
+			return err
+		}
+
+		selectionReq := models.CreatePRRequest{
+			PullRequestID: pr.PullRequestID,
+			AuthorID:      pr.AuthorID,
+			Repository:    req.Repository,
+			ChangedFiles:  req.ChangedFiles,
+		}
+		if pr.Repository != "" {
+			selectionReq.Repository = pr.Repository
+		}
+		selected, err := s.selectInitialReviewers(ctx, tx, team, settings, selectionReq, pr.Labels, pr.TargetReviewers)
+		if err != nil {
+			return err
+		}
+
+		err = s.PullRequestServ.UpdatePRStatusTx(ctx, tx, pr.PullRequestID, pr.Status, models.PRStatusOpen)
+		if err != nil {
+			return err
+		}
+
+		err = s.PullRequestServ.AddPRHistoryTx(ctx, tx, models.PRHistoryEntry{
+			PullRequestID: pr.PullRequestID,
+			Event:         models.PREventReady,
+			FromStatus:    pr.Status,
+			ToStatus:      models.PRStatusOpen,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = s.PullRequestServ.UpdatePRReviewersTx(ctx, tx, pr.PullRequestID, selected.reviewers, selected.crossTeam)
+		if err != nil {
+			return err
+		}
+
+		decisions := selected.trace.decisions(pr.PullRequestID, models.AssignmentActionCreate, "", selected.picks)
+		if err := s.PullRequestServ.CreateAssignmentDecisionsTx(ctx, tx, decisions); err != nil {
+			return err
+		}
+
+		pr, err = s.PullRequestServ.GetPRByIDTx(ctx, tx, pr.PullRequestID)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return err
+		}
+
+		result = pr
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ClosePR закрывает PR без merge. Ревьюеры остаются назначенными,
+// но закрытый PR не считается в их нагрузке
+func (s *PullRequestService) ClosePR(ctx context.Context, prID string) (*models.PullRequest, error) {
+	return s.transitionPR(ctx, prID, models.PRStatusClosed, models.PREventClosed)
+}
+
+// ReopenPR переоткрывает закрытый PR с теми же ревьюерами
+func (s *PullRequestService) ReopenPR(ctx context.Context, prID string) (*models.PullRequest, error) {
+	return s.transitionPR(ctx, prID, models.PRStatusReopened, models.PREventReopened)
+}
+
+func (s *PullRequestService) transitionPR(ctx context.Context, prID string, to string, event string) (*models.PullRequest, error) {
+	var result *models.PullRequest
+
+	err := s.executeWithRetry(ctx, func() error {
+		tx, err := s.PullRequestServ.PRBeginTx(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+
+		pr, err := s.PullRequestServ.GetPRByIDTx(ctx, tx, prID)
+		if err != nil {
+			return models.ErrNotFound
+		}
+
+		if !models.CanTransition(pr.Status, to) {
+			return models.ErrInvalidTransition
+		}
+
+		err = s.PullRequestServ.UpdatePRStatusTx(ctx, tx, prID, pr.Status, to)
+		if err != nil {
+			return err
+		}
+
+		err = s.PullRequestServ.AddPRHistoryTx(ctx, tx, models.PRHistoryEntry{
+			PullRequestID: prID,
+			Event:         event,
+			FromStatus:    pr.Status,
+			ToStatus:      to,
+		})
+		if err != nil {
+			return err
+		}
+
+		pr, err = s.PullRequestServ.GetPRByIDTx(ctx, tx, prID)
+		if err != nil {
 			return err
 		}
 
@@ -105,7 +414,7 @@ func (s *PullRequestService) CreatePR(ctx context.Context, req models.CreatePRRe
 			return err
 		}
 
-		result = &pr
+		result = pr
 		return nil
 	})
 
@@ -116,8 +425,9 @@ func (s *PullRequestService) CreatePR(ctx context.Context, req models.CreatePRRe
 	return result, nil
 }
 
-func (s *PullRequestService) MergePR(ctx context.Context, prID string) (*models.PullRequest, error) {
+func (s *PullRequestService) MergePR(ctx context.Context, req models.MergePRRequest) (*models.PullRequest, error) {
 	var result *models.PullRequest
+	prID := req.PullRequestID
 
 	err := s.executeWithRetry(ctx, func() error {
 		tx, err := s.PullRequestServ.PRBeginTx(ctx)
@@ -131,16 +441,66 @@ func (s *PullRequestService) MergePR(ctx context.Context, prID string) (*models.
 			return models.ErrNotFound
 		}
 
-		if pr.Status == "MERGED" {
+		if pr.Status == models.PRStatusMerged {
 			result = pr
 			return nil
 		}
 
+		if !models.CanTransition(pr.Status, models.PRStatusMerged) {
+			return models.ErrInvalidTransition
+		}
+
+		openParents, err := s.openParentsTx(ctx, tx, pr)
+		if err != nil {
+			return err
+		}
+		if len(openParents) > 0 {
+			return &models.DependencyOpenError{OpenParents: openParents}
+		}
+
+		_, settings, err := s.prTeamTx(ctx, tx, pr.AuthorID, pr.Repository, pr.TeamName)
+		if err != nil {
+			return err
+		}
+
+		reviews, err := s.PullRequestServ.GetPRReviewsTx(ctx, tx, prID)
+		if err != nil {
+			return err
+		}
+
+		unresolved, err := s.unresolvedThreads(ctx, tx, settings, prID)
+		if err != nil {
+			return err
+		}
+
+		unmet := checkMergePolicy(settings, pr, reviews, unresolved)
+		if len(unmet) > 0 && !req.Force {
+			return &models.MergeBlockedError{Unmet: unmet}
+		}
+
 		err = s.PullRequestServ.MergePRTx(ctx, tx, prID)
 		if err != nil {
 			return err
 		}
 
+		details := map[string]any{}
+		if req.Force {
+			details["override"] = true
+			details["overridden_conditions"] = unmet
+			details["reason"] = req.Reason
+		}
+		err = s.PullRequestServ.AddPRHistoryTx(ctx, tx, models.PRHistoryEntry{
+			PullRequestID: prID,
+			Event:         models.PREventMerged,
+			FromStatus:    pr.Status,
+			ToStatus:      models.PRStatusMerged,
+			Actor:         req.Actor,
+			Details:       details,
+		})
+		if err != nil {
+			return err
+		}
+
 		pr, err = s.PullRequestServ.GetPRByIDTx(ctx, tx, prID)
 		if err != nil {
 			return err
@@ -177,31 +537,346 @@ func (s *PullRequestService) ReassignReviewer(ctx context.Context, req models.Re
 			return models.ErrNotFound
 		}
 
-		if pr.Status == "MERGED" {
+		newReviewer, _, err := s.reassignTx(ctx, tx, pr, req.OldUserID)
+		if err != nil {
+			return err
+		}
+
+		updatedPR, err := s.PullRequestServ.GetPRByIDTx(ctx, tx, req.PullRequestID)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return err
+		}
+
+		resultPR = updatedPR
+		resultReviewer = newReviewer
+		return nil
+	})
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	return resultPR, resultReviewer, nil
+}
+
+// OpenReviewsTx - PR, в которых у пользователя OPEN ревью. Непустой teamName -
+// только PR этой команды (см. prTeamTx)
+func (s *PullRequestService) OpenReviewsTx(ctx context.Context, tx pgx.Tx, userID string, teamName string) ([]string, error) {
+	prs, err := s.PullRequestServ.GetOpenPRsByReviewerTx(ctx, tx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(prs))
+	for _, pr := range prs {
+		if teamName != "" {
+			team, _, err := s.prTeamTx(ctx, tx, pr.AuthorID, pr.Repository, pr.TeamName)
+			if err == models.ErrNoTeam {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			if team.TeamName != teamName {
+				continue
+			}
+		}
+		ids = append(ids, pr.PullRequestID)
+	}
+	return ids, nil
+}
+
+// ReassignOpenReviewsTx снимает пользователя с его OPEN ревью (с непустым teamName -
+// только в PR этой команды) в переданной транзакции, см. ReassignReviewsTx
+func (s *PullRequestService) ReassignOpenReviewsTx(ctx context.Context, tx pgx.Tx, userID string, teamName string) ([]models.ReviewReassignment, error) {
+	prIDs, err := s.OpenReviewsTx(ctx, tx, userID, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.ReassignReviewsTx(ctx, tx, userID, prIDs)
+}
+
+// ReassignReviewsTx снимает пользователя с OPEN ревью в PR prIDs в переданной транзакции.
+// Если замены нет (или у PR больше нет команды - ErrNoTeam), ревьюер просто убирается из PR
+func (s *PullRequestService) ReassignReviewsTx(ctx context.Context, tx pgx.Tx, userID string, prIDs []string) ([]models.ReviewReassignment, error) {
+	prs, err := s.PullRequestServ.GetOpenPRsByReviewerTx(ctx, tx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	reassigned := []models.ReviewReassignment{}
+	for i := range prs {
+		pr := &prs[i]
+		if !contains(prIDs, pr.PullRequestID) {
+			continue
+		}
+
+		newReviewer, isCrossTeam, err := s.reassignTx(ctx, tx, pr, userID)
+		if err == models.ErrNoCandidate || err == models.ErrNoTeam {
+			err = s.PullRequestServ.UpdatePRReviewersTx(ctx, tx, pr.PullRequestID,
+				removeFromSlice(pr.AssignedReviewers, userID),
+				removeFromSlice(pr.CrossTeamReviewers, userID))
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		reassigned = append(reassigned, models.ReviewReassignment{
+			PullRequestID: pr.PullRequestID,
+			OldUserID:     userID,
+			ReplacedBy:    newReviewer,
+			CrossTeam:     isCrossTeam,
+		})
+	}
+
+	return reassigned, nil
+}
+
+// reassignTx заменяет oldUserID на PR другим ревьюером в уже открытой транзакции
+func (s *PullRequestService) reassignTx(ctx context.Context, tx pgx.Tx, pr *models.PullRequest, oldUserID string) (string, bool, error) {
+	if pr.Status == models.PRStatusMerged {
+		return "", false, models.ErrPRMerged
+	}
+
+	if !pr.IsOpen() {
+		return "", false, models.ErrPRNotOpen
+	}
+
+	if !contains(pr.AssignedReviewers, oldUserID) {
+		return "", false, models.ErrNotAssigned
+	}
+
+	team, settings, err := s.prTeamTx(ctx, tx, pr.AuthorID, pr.Repository, pr.TeamName)
+	if err != nil {
+		return "", false, err
+	}
+
+	trace := newSelectionTrace()
+	newReviewer, isCrossTeam, err := s.findReplacementReviewer(ctx, tx, team, settings, pr, oldUserID, trace)
+	if err != nil {
+		return "", false, err
+	}
+
+	newReviewers := replaceInSlice(pr.AssignedReviewers, oldUserID, newReviewer)
+	crossTeam := removeFromSlice(pr.CrossTeamReviewers, oldUserID)
+	if isCrossTeam {
+		crossTeam = append(crossTeam, newReviewer)
+	}
+
+	err = s.PullRequestServ.UpdatePRReviewersTx(ctx, tx, pr.PullRequestID, newReviewers, crossTeam)
+	if err != nil {
+		return "", false, err
+	}
+
+	pick := models.ReviewerPick{UserID: newReviewer, Source: models.ReviewerSourceTeam}
+	if isCrossTeam {
+		pick.Source = models.ReviewerSourceFallback
+	}
+	decisions := trace.decisions(pr.PullRequestID, models.AssignmentActionReassign, oldUserID, []models.ReviewerPick{pick})
+	if err := s.PullRequestServ.CreateAssignmentDecisionsTx(ctx, tx, decisions); err != nil {
+		return "", false, err
+	}
+
+	return newReviewer, isCrossTeam, nil
+}
+
+func (s *PullRequestService) GetPR(ctx context.Context, prID string, explain bool) (*models.PullRequest, []models.AssignmentDecision, error) {
+	var result *models.PullRequest
+	var decisions []models.AssignmentDecision
+
+	err := s.executeWithRetry(ctx, func() error {
+		tx, err := s.PullRequestServ.PRBeginTx(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+
+		pr, err := s.PullRequestServ.GetPRByIDTx(ctx, tx, prID)
+		if err != nil {
+			return err
+		}
+
+		pr.Reviews, err = s.PullRequestServ.GetPRReviewsTx(ctx, tx, prID)
+		if err != nil {
+			return err
+		}
+
+		var found []models.AssignmentDecision
+		if explain {
+			found, err = s.PullRequestServ.GetAssignmentDecisionsTx(ctx, tx, prID)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return err
+		}
+
+		result = pr
+		decisions = found
+		return nil
+	})
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return result, decisions, nil
+}
+
+// UpdatePR меняет метаданные PR, если с req.Version его никто не изменил.
+// Изменение пишется в историю: какие поля, старое и новое значение, новая версия
+func (s *PullRequestService) UpdatePR(ctx context.Context, req models.UpdatePRRequest) (*models.PullRequest, error) {
+	var result *models.PullRequest
+
+	err := s.executeWithRetry(ctx, func() error {
+		tx, err := s.PullRequestServ.PRBeginTx(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+
+		pr, err := s.PullRequestServ.GetPRByIDTx(ctx, tx, req.PullRequestID)
+		if err != nil {
+			return err
+		}
+
+		if pr.Version != req.Version {
+			return models.ErrVersionConflict
+		}
+
+		if pr.Status == models.PRStatusMerged {
 			return models.ErrPRMerged
 		}
 
-		if !contains(pr.AssignedReviewers, req.OldUserID) {
-			return models.ErrNotAssigned
+		changes := map[string]any{}
+		updated := *pr
+		if req.PullRequestName != nil && *req.PullRequestName != pr.PullRequestName {
+			changes["pull_request_name"] = map[string]any{"from": pr.PullRequestName, "to": *req.PullRequestName}
+			updated.PullRequestName = *req.PullRequestName
+		}
+		if req.Description != nil && *req.Description != pr.Description {
+			changes["description"] = map[string]any{"from": pr.Description, "to": *req.Description}
+			updated.Description = *req.Description
+		}
+		if req.Labels != nil {
+			labels := normalizeTags(*req.Labels)
+			if strings.Join(labels, ",") != strings.Join(pr.Labels, ",") {
+				changes["labels"] = map[string]any{"from": pr.Labels, "to": labels}
+				updated.Labels = labels
+			}
+		}
+
+		if len(changes) == 0 {
+			result = pr
+			return nil
 		}
 
-		author, err := s.userStorage.GetUserTx(ctx, tx, pr.AuthorID)
+		err = s.PullRequestServ.UpdatePRMetadataTx(ctx, tx, updated, req.Version)
 		if err != nil {
-			return models.ErrNotFound
+			return err
 		}
 
-		newReviewer, err := s.findReplacementReviewer(ctx, tx, author.TeamName, pr.AssignedReviewers, req.OldUserID, pr.AuthorID)
+		err = s.PullRequestServ.AddPRHistoryTx(ctx, tx, models.PRHistoryEntry{
+			PullRequestID: pr.PullRequestID,
+			Event:         models.PREventEdited,
+			FromStatus:    pr.Status,
+			ToStatus:      pr.Status,
+			Actor:         req.Actor,
+			Details: map[string]any{
+				"changes": changes,
+				"version": req.Version + 1,
+			},
+		})
 		if err != nil {
-			return models.ErrNoCandidate
+			return err
 		}
 
-		newReviewers := replaceInSlice(pr.AssignedReviewers, req.OldUserID, newReviewer)
-		err = s.PullRequestServ.UpdatePRReviewersTx(ctx, tx, req.PullRequestID, newReviewers)
+		pr, err = s.PullRequestServ.GetPRByIDTx(ctx, tx, pr.PullRequestID)
 		if err != nil {
 			return err
 		}
 
-		updatedPR, err := s.PullRequestServ.GetPRByIDTx(ctx, tx, req.PullRequestID)
+		if err := tx.Commit(ctx); err != nil {
+			return err
+		}
+
+		result = pr
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *PullRequestService) GetPRHistory(ctx context.Context, prID string) ([]models.PRHistoryEntry, error) {
+	if _, err := s.PullRequestServ.GetPRByIDTx(ctx, nil, prID); err != nil {
+		return nil, err
+	}
+
+	return s.PullRequestServ.GetPRHistoryTx(ctx, nil, prID)
+}
+
+func (s *PullRequestService) SubmitReview(ctx context.Context, req models.SubmitReviewRequest) (*models.PullRequestReview, error) {
+	if !models.IsReviewDecision(req.State) {
+		return nil, models.ErrInvalidReviewState
+	}
+
+	return s.changeReview(ctx, req.PullRequestID, req.ReviewerID, func(tx pgx.Tx) error {
+		return s.PullRequestServ.SubmitReviewTx(ctx, tx, req)
+	})
+}
+
+// RerequestReview снова запрашивает ревью у ревьюера - его прошлое решение сбрасывается
+func (s *PullRequestService) RerequestReview(ctx context.Context, prID string, reviewerID string) (*models.PullRequestReview, error) {
+	return s.changeReview(ctx, prID, reviewerID, func(tx pgx.Tx) error {
+		return s.PullRequestServ.ResetReviewTx(ctx, tx, prID, reviewerID)
+	})
+}
+
+// changeReview проверяет, что PR ждет ревью и reviewerID на нем назначен,
+// выполняет change и возвращает обновленное ревью
+func (s *PullRequestService) changeReview(ctx context.Context, prID string, reviewerID string, change func(tx pgx.Tx) error) (*models.PullRequestReview, error) {
+	var result *models.PullRequestReview
+
+	err := s.executeWithRetry(ctx, func() error {
+		tx, err := s.PullRequestServ.PRBeginTx(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+
+		pr, err := s.PullRequestServ.GetPRByIDTx(ctx, tx, prID)
+		if err != nil {
+			return models.ErrNotFound
+		}
+
+		if pr.Status == models.PRStatusMerged {
+			return models.ErrPRMerged
+		}
+
+		if !pr.IsOpen() {
+			return models.ErrPRNotOpen
+		}
+
+		if !contains(pr.AssignedReviewers, reviewerID) {
+			return models.ErrNotAssigned
+		}
+
+		if err := change(tx); err != nil {
+			return err
+		}
+
+		reviews, err := s.PullRequestServ.GetPRReviewsTx(ctx, tx, prID)
 		if err != nil {
 			return err
 		}
@@ -210,19 +885,22 @@ func (s *PullRequestService) ReassignReviewer(ctx context.Context, req models.Re
 			return err
 		}
 
-		resultPR = updatedPR
-		resultReviewer = newReviewer
+		for i := range reviews {
+			if reviews[i].ReviewerID == reviewerID {
+				result = &reviews[i]
+			}
+		}
 		return nil
 	})
 
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
 
-	return resultPR, resultReviewer, nil
+	return result, nil
 }
 
-func (s *PullRequestService) GetUserReviews(ctx context.Context, userID string) ([]models.PullRequestShort, error) {
+func (s *PullRequestService) GetUserReviews(ctx context.Context, userID string, repository string) ([]models.PullRequestShort, error) {
 	var result []models.PullRequestShort
 
 	err := s.executeWithRetry(ctx, func() error {
@@ -237,7 +915,7 @@ func (s *PullRequestService) GetUserReviews(ctx context.Context, userID string)
 			return models.ErrNotFound
 		}
 
-		prs, err := s.PullRequestServ.GetPRsByReviewerTx(ctx, tx, userID)
+		prs, err := s.PullRequestServ.GetPRsByReviewerTx(ctx, tx, userID, repository)
 		if err != nil {
 			return err
 		}
@@ -257,46 +935,97 @@ func (s *PullRequestService) GetUserReviews(ctx context.Context, userID string)
 	return result, nil
 }
 
-func (s *PullRequestService) findReviewersFromTeam(team *models.Team, authorID string) []string {
-	var reviewers []string
-	for _, member := range team.Members {
-		if member.UserID == authorID || !member.IsActive {
-			continue
-		}
-		reviewers = append(reviewers, member.UserID)
-		if len(reviewers) >= 2 {
-			break
+// newPRID - id создаваемого PR. В репозитории он выводится из номера,
+// переданный pull_request_id тогда должен с ним совпадать
+func newPRID(req models.CreatePRRequest) (string, error) {
+	if req.Number == 0 {
+		if req.PullRequestID == "" {
+			return "", models.ErrInvalidPRRef
 		}
+		return req.PullRequestID, nil
+	}
+
+	if req.Repository == "" || req.Number < 0 {
+		return "", models.ErrInvalidPRRef
 	}
-	return reviewers
+
+	prID := models.RepositoryPRID(req.Repository, req.Number)
+	if req.PullRequestID != "" && req.PullRequestID != prID {
+		return "", models.ErrInvalidPRRef
+	}
+
+	return prID, nil
 }
 
-func (s *PullRequestService) findReplacementReviewer(ctx context.Context, tx pgx.Tx, teamName string, currentReviewers []string, oldUserID string, authorID string) (string, error) {
-	team, err := s.teamStorage.GetTeamInfoTx(ctx, tx, teamName)
+// registeredRepositoryTx - name, если такой репозиторий зарегистрирован, иначе пустая строка.
+// Незарегистрированный репозиторий, как и до /repository/add, нужен только для поиска
+// CODEOWNERS и вместе с PR не сохраняется
+func (s *PullRequestService) registeredRepositoryTx(ctx context.Context, tx pgx.Tx, name string) (string, error) {
+	if name == "" {
+		return "", nil
+	}
+
+	_, err := s.repoStorage.GetRepositoryTx(ctx, tx, name)
+	if err == models.ErrNotFound {
+		return "", nil
+	}
 	if err != nil {
 		return "", err
 	}
+	return name, nil
+}
 
-	for _, member := range team.Members {
-		if member.UserID == authorID ||
-			!member.IsActive ||
-			contains(currentReviewers, member.UserID) ||
-			member.UserID == oldUserID {
-			continue
+// prTeamTx - команда, из которой выбираются ревьюеры PR и чья политика merge действует,
+// и ее настройки. Команда, выбранная при создании PR (prTeam), если есть; иначе команда
+// по умолчанию репозитория; иначе основная команда автора (или первая, если основной нет).
+// Незарегистрированный репозиторий не учитывается. Если команды так и нет - ErrNoTeam.
+// Стратегия и число ревьюеров репозитория, если заданы, перекрывают настройки команды
+func (s *PullRequestService) prTeamTx(ctx context.Context, tx pgx.Tx, authorID string, repository string, prTeam string) (*models.Team, *models.TeamSettings, error) {
+	author, err := s.userStorage.GetUserTx(ctx, tx, authorID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	teamName := author.TeamName
+	var repo *models.Repository
+	if repository != "" {
+		repo, err = s.repoStorage.GetRepositoryTx(ctx, tx, repository)
+		switch {
+		case err == models.ErrNotFound:
+			repo = nil
+		case err != nil:
+			return nil, nil, err
+		case repo.DefaultTeam != "":
+			teamName = repo.DefaultTeam
 		}
-		return member.UserID, nil
+	}
+	if prTeam != "" {
+		teamName = prTeam
+	}
+	if teamName == "" {
+		return nil, nil, models.ErrNoTeam
 	}
 
-	return "", models.ErrNoCandidate
-}
+	team, err := s.teamStorage.GetTeamInfoTx(ctx, tx, teamName)
+	if err != nil {
+		return nil, nil, err
+	}
 
-func contains(slice []string, item string) bool {
-	for _, s := range slice {
-		if s == item {
-			return true
+	settings, err := s.teamStorage.GetTeamSettingsTx(ctx, tx, team.TeamName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if repo != nil {
+		if repo.ReviewerStrategy != "" {
+			settings.ReviewerStrategy = repo.ReviewerStrategy
+		}
+		if repo.ReviewerCount != nil {
+			settings.ReviewerCount = *repo.ReviewerCount
 		}
 	}
-	return false
+
+	return team, settings, nil
 }
 
 func replaceInSlice(slice []string, old string, new string) []string {
@@ -311,6 +1040,16 @@ func replaceInSlice(slice []string, old string, new string) []string {
 	return result
 }
 
+func removeFromSlice(slice []string, item string) []string {
+	result := make([]string, 0, len(slice))
+	for _, s := range slice {
+		if s != item {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
 func isUniqueConstraintError(err error) bool {
 	if err == nil {
 		return false
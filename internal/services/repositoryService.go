@@ -0,0 +1,171 @@
+package services
+
+/*
+Функции:
+	1. Регистрация репозитория
+	2. Получение репозитория
+	3. Изменение настроек репозитория
+
+Настройки репозитория перекрывают настройки команды при выборе ревьюеров
+для PR этого репозитория (см. prTeamTx в pullrequestService.go)
+*/
+
+import (
+	"context"
+	"subscription-budget/internal/models"
+	"subscription-budget/internal/storage"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type RepositoryService struct {
+	repoStorage storage.RepositoryStorage
+	teamStorage storage.TeamStorage
+	strategies  *StrategyRegistry
+}
+
+func NewRepositoryService(repoStorage storage.RepositoryStorage, teamStorage storage.TeamStorage, strategies *StrategyRegistry) *RepositoryService {
+	return &RepositoryService{
+		repoStorage: repoStorage,
+		teamStorage: teamStorage,
+		strategies:  strategies,
+	}
+}
+
+func (s *RepositoryService) executeWithRetry(ctx context.Context, operation func() error) error {
+	maxRetries := 3
+	var lastErr error
+
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(time.Duration(attempt) * 100 * time.Millisecond):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		err := operation()
+		if err == nil {
+			return nil
+		}
+
+		lastErr = err
+	}
+
+	return lastErr
+}
+
+func (s *RepositoryService) CreateRepository(ctx context.Context, repo models.Repository) (*models.Repository, error) {
+	var result *models.Repository
+
+	err := s.executeWithRetry(ctx, func() error {
+		tx, err := s.repoStorage.RepositoryBeginTx(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+
+		if err := s.validateRepository(ctx, tx, repo); err != nil {
+			return err
+		}
+
+		if err := s.repoStorage.CreateRepositoryTx(ctx, tx, repo); err != nil {
+			return err
+		}
+
+		created, err := s.repoStorage.GetRepositoryTx(ctx, tx, repo.Name)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return err
+		}
+
+		result = created
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *RepositoryService) GetRepository(ctx context.Context, name string) (*models.Repository, error) {
+	return s.repoStorage.GetRepositoryTx(ctx, nil, name)
+}
+
+func (s *RepositoryService) UpdateRepositorySettings(ctx context.Context, update models.RepositorySettingsUpdate) (*models.Repository, error) {
+	var result *models.Repository
+
+	err := s.executeWithRetry(ctx, func() error {
+		tx, err := s.repoStorage.RepositoryBeginTx(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+
+		repo, err := s.repoStorage.GetRepositoryTx(ctx, tx, update.Name)
+		if err != nil {
+			return err
+		}
+
+		if update.DefaultTeam != nil {
+			repo.DefaultTeam = *update.DefaultTeam
+		}
+		if update.ReviewerStrategy != nil {
+			repo.ReviewerStrategy = *update.ReviewerStrategy
+		}
+		if update.ReviewerCount != nil {
+			repo.ReviewerCount = update.ReviewerCount
+		}
+		if update.ResetReviewerCount {
+			repo.ReviewerCount = nil
+		}
+
+		if err := s.validateRepository(ctx, tx, *repo); err != nil {
+			return err
+		}
+
+		if err := s.repoStorage.UpdateRepositoryTx(ctx, tx, *repo); err != nil {
+			return err
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return err
+		}
+
+		result = repo
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *RepositoryService) validateRepository(ctx context.Context, tx pgx.Tx, repo models.Repository) error {
+	if repo.ReviewerStrategy != "" {
+		if _, err := s.strategies.Get(repo.ReviewerStrategy); err != nil {
+			return err
+		}
+	}
+
+	if repo.DefaultTeam != "" {
+		team, err := s.teamStorage.GetTeamInfoTx(ctx, tx, repo.DefaultTeam)
+		if err != nil {
+			return models.ErrNotFound
+		}
+		if team.ArchivedAt != nil {
+			return models.ErrTeamArchived
+		}
+	}
+
+	return nil
+}
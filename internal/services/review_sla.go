@@ -0,0 +1,261 @@
+package services
+
+/*
+SLA ревью - у команды review_sla_hours рабочих часов на первый ответ ревьюера:
+	1. WorkingHours - рабочий день (часы начала и конца, часовой пояс), выходные не считаются
+	2. EscalateOverdueReviews - находит PENDING-ревью в открытых PR, у которых вышел SLA
+	   команды PR (см. prTeamTx), помечает их просроченными и пишет событие review_overdue
+	   в историю PR. Если у команды sla_reassign, ревью переназначается через reassignTx
+	3. ReviewSLAWorker - фоновый воркер внутри приложения, проходит раз в interval
+	   и отдает итог прохода в StatService
+
+Каждое ревью эскалируется в своей сериализуемой транзакции с повторами; ошибка в одном
+ревью пишется в лог и не останавливает проход. Ревью в PR команд без SLA не читаются вовсе.
+Повторный запрос ревью (rerequestReview) снимает отметку о просрочке.
+*/
+
+import (
+	"context"
+	"log/slog"
+	"subscription-budget/internal/models"
+	"time"
+)
+
+// WorkingHours - рабочий день с Start до End часов (в Location), с понедельника по пятницу
+type WorkingHours struct {
+	Start    int
+	End      int
+	Location *time.Location
+}
+
+// Deadline - момент, когда от from пройдет hours рабочих часов.
+// Если рабочий день пустой (Start >= End), считает календарные часы, а не зацикливается
+func (w WorkingHours) Deadline(from time.Time, hours int) time.Time {
+	remaining := time.Duration(hours) * time.Hour
+	if w.Start >= w.End {
+		return from.Add(remaining)
+	}
+	t := from.In(w.Location)
+
+	for {
+		dayStart := time.Date(t.Year(), t.Month(), t.Day(), w.Start, 0, 0, 0, w.Location)
+		dayEnd := time.Date(t.Year(), t.Month(), t.Day(), w.End, 0, 0, 0, w.Location)
+
+		if isWeekend(t) || !t.Before(dayEnd) {
+			t = dayStart.AddDate(0, 0, 1)
+			continue
+		}
+		if t.Before(dayStart) {
+			t = dayStart
+		}
+
+		left := dayEnd.Sub(t)
+		if remaining <= left {
+			return t.Add(remaining)
+		}
+		remaining -= left
+		t = dayEnd
+	}
+}
+
+func isWeekend(t time.Time) bool {
+	return t.Weekday() == time.Saturday || t.Weekday() == time.Sunday
+}
+
+// EscalateOverdueReviews эскалирует ревью, у которых к now вышел SLA команды.
+// Возвращает эскалации, сделанные за этот проход; ошибка - только если прочитать ревью
+// не вышло или ctx отменен
+func (s *PullRequestService) EscalateOverdueReviews(ctx context.Context, hours WorkingHours, now time.Time) ([]models.ReviewEscalation, error) {
+	reviews, err := s.PullRequestServ.GetPendingReviewsTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	escalations := []models.ReviewEscalation{}
+	for _, review := range reviews {
+		escalation, err := s.escalateReview(ctx, review, hours, now)
+		if ctx.Err() != nil {
+			return escalations, ctx.Err()
+		}
+		if err != nil {
+			slog.Error("Failed to escalate review",
+				"pull_request_id", review.PullRequestID,
+				"reviewer_id", review.ReviewerID,
+				"error", err)
+			continue
+		}
+		if escalation != nil {
+			escalations = append(escalations, *escalation)
+		}
+	}
+
+	return escalations, nil
+}
+
+// escalateReview эскалирует одно ревью; nil, если SLA еще не вышел или ревью уже не ждет ответа
+func (s *PullRequestService) escalateReview(ctx context.Context, review models.PullRequestReview, hours WorkingHours, now time.Time) (*models.ReviewEscalation, error) {
+	var result *models.ReviewEscalation
+
+	err := s.executeWithRetry(ctx, func() error {
+		result = nil
+
+		tx, err := s.PullRequestServ.PRBeginTx(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+
+		pr, err := s.PullRequestServ.GetPRByIDTx(ctx, tx, review.PullRequestID)
+		if err != nil {
+			return err
+		}
+
+		if !pr.IsOpen() || !contains(pr.AssignedReviewers, review.ReviewerID) {
+			return nil
+		}
+
+		_, settings, err := s.prTeamTx(ctx, tx, pr.AuthorID, pr.Repository, pr.TeamName)
+		if err == models.ErrNoTeam {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if settings.ReviewSLAHours == 0 {
+			return nil
+		}
+
+		deadline := hours.Deadline(review.RequestedAt, settings.ReviewSLAHours)
+		if now.Before(deadline) {
+			return nil
+		}
+
+		err = s.PullRequestServ.MarkReviewOverdueTx(ctx, tx, pr.PullRequestID, review.ReviewerID)
+		if err == models.ErrNotAssigned {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		escalation := models.ReviewEscalation{
+			PullRequestID: pr.PullRequestID,
+			ReviewerID:    review.ReviewerID,
+			RequestedAt:   review.RequestedAt,
+			Deadline:      deadline,
+		}
+
+		if settings.SLAReassign {
+			newReviewer, _, err := s.reassignTx(ctx, tx, pr, review.ReviewerID)
+			switch err {
+			case nil:
+				escalation.ReplacedBy = newReviewer
+			case models.ErrNoCandidate:
+				// замены нет - ревью остается за просрочившим ревьюером
+			default:
+				return err
+			}
+		}
+
+		details := map[string]any{
+			"reviewer_id":  escalation.ReviewerID,
+			"requested_at": escalation.RequestedAt,
+			"deadline":     escalation.Deadline,
+			"sla_hours":    settings.ReviewSLAHours,
+		}
+		if escalation.ReplacedBy != "" {
+			details["replaced_by"] = escalation.ReplacedBy
+		}
+		err = s.PullRequestServ.AddPRHistoryTx(ctx, tx, models.PRHistoryEntry{
+			PullRequestID: pr.PullRequestID,
+			Event:         models.PREventReviewOverdue,
+			FromStatus:    pr.Status,
+			ToStatus:      pr.Status,
+			Details:       details,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return err
+		}
+
+		result = &escalation
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// CountOverdueReviews - сколько просроченных ревью еще ждут ответа
+func (s *PullRequestService) CountOverdueReviews(ctx context.Context) (int, error) {
+	return s.PullRequestServ.CountOverdueReviewsTx(ctx, nil)
+}
+
+// ReviewEscalator эскалирует ревью, просроченные по SLA
+type ReviewEscalator interface {
+	EscalateOverdueReviews(ctx context.Context, hours WorkingHours, now time.Time) ([]models.ReviewEscalation, error)
+	CountOverdueReviews(ctx context.Context) (int, error)
+}
+
+type ReviewSLAWorker struct {
+	escalator ReviewEscalator
+	hours     WorkingHours
+	interval  time.Duration
+	stats     *StatService
+}
+
+func NewReviewSLAWorker(escalator ReviewEscalator, hours WorkingHours, interval time.Duration, stats *StatService) *ReviewSLAWorker {
+	return &ReviewSLAWorker{
+		escalator: escalator,
+		hours:     hours,
+		interval:  interval,
+		stats:     stats,
+	}
+}
+
+// Run проверяет SLA раз в interval до отмены ctx
+func (w *ReviewSLAWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.check(ctx)
+		}
+	}
+}
+
+func (w *ReviewSLAWorker) check(ctx context.Context) {
+	escalations, err := w.escalator.EscalateOverdueReviews(ctx, w.hours, time.Now())
+	if err != nil && ctx.Err() == nil {
+		slog.Error("Failed to escalate overdue reviews", "error", err)
+	}
+
+	for _, e := range escalations {
+		slog.Warn("Review is past SLA",
+			"pull_request_id", e.PullRequestID,
+			"reviewer_id", e.ReviewerID,
+			"deadline", e.Deadline,
+			"replaced_by", e.ReplacedBy)
+	}
+
+	overdue, err := w.escalator.CountOverdueReviews(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Error("Failed to count overdue reviews", "error", err)
+		}
+		return
+	}
+
+	w.stats.RecordSLACheck(overdue, len(escalations))
+}
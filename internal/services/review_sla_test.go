@@ -0,0 +1,64 @@
+package services
+
+/*
+Проверка дедлайна SLA в рабочих часах (WorkingHours.Deadline):
+	1. В пределах дня, перенос на следующий день, несколько дней
+	2. Старт до и после рабочего дня, в выходные, в пятницу вечером
+	3. Рабочие часы в другом часовом поясе
+	4. Пустой рабочий день - календарные часы
+*/
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkingHoursDeadline(t *testing.T) {
+	hours := WorkingHours{Start: 9, End: 18, Location: time.UTC}
+	at := func(day, hour, minute int) time.Time {
+		// 1 января 2024 - понедельник
+		return time.Date(2024, time.January, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name  string
+		hours WorkingHours
+		from  time.Time
+		sla   int
+		want  time.Time
+	}{
+		{name: "within one day", hours: hours, from: at(1, 10, 30), sla: 4, want: at(1, 14, 30)},
+		{name: "ends exactly at end of day", hours: hours, from: at(1, 9, 0), sla: 9, want: at(1, 18, 0)},
+		{name: "spills into next day", hours: hours, from: at(1, 16, 0), sla: 4, want: at(2, 11, 0)},
+		{name: "starts before working hours", hours: hours, from: at(1, 7, 15), sla: 1, want: at(1, 10, 0)},
+		{name: "starts after working hours", hours: hours, from: at(1, 20, 0), sla: 2, want: at(2, 11, 0)},
+		{name: "zero hours after working hours", hours: hours, from: at(1, 20, 0), sla: 0, want: at(2, 9, 0)},
+		{name: "starts on saturday", hours: hours, from: at(6, 12, 0), sla: 3, want: at(8, 12, 0)},
+		{name: "starts on sunday night", hours: hours, from: at(7, 23, 0), sla: 1, want: at(8, 10, 0)},
+		{name: "friday evening skips weekend", hours: hours, from: at(5, 17, 0), sla: 2, want: at(8, 10, 0)},
+		{name: "multi-day", hours: hours, from: at(1, 9, 0), sla: 20, want: at(3, 11, 0)},
+		{name: "multi-day across weekend", hours: hours, from: at(4, 12, 0), sla: 24, want: at(8, 18, 0)},
+		{
+			name:  "working hours in another time zone",
+			hours: WorkingHours{Start: 9, End: 18, Location: time.FixedZone("UTC+3", 3*60*60)},
+			from:  at(1, 5, 0),
+			sla:   1,
+			want:  at(1, 7, 0),
+		},
+		{
+			name:  "empty working day counts calendar hours",
+			hours: WorkingHours{Start: 18, End: 9, Location: time.UTC},
+			from:  at(5, 17, 0),
+			sla:   10,
+			want:  at(6, 3, 0),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.hours.Deadline(tt.from, tt.sla)
+			assert.True(t, tt.want.Equal(got), "want %s, got %s", tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package services
+
+/*
+Добор ревьюеров в PR, созданные с недобором (ревьюеров меньше target_reviewers):
+	1. BackfillReviewers - проходит по OPEN PR с недобором и добирает
+	   недостающих ревьюеров: сначала команда PR (см. prTeamTx), затем родительские
+	   и запасные команды
+	2. ReviewerReconciler - фоновый воркер внутри приложения.
+	   Запускает добор по команде, когда в ней появилась мощность
+	   (юзера активировали или он вступил в команду), и раз в interval по всем PR
+
+Каждый PR добирается в своей сериализуемой транзакции с повторами, как CreatePR.
+Ошибка в одном PR пишется в лог и не останавливает добор остальных.
+CODEOWNERS при доборе не учитываются - список измененных файлов в PR не хранится.
+*/
+
+import (
+	"context"
+	"log/slog"
+	"subscription-budget/internal/models"
+	"time"
+)
+
+// BackfillReviewers добирает ревьюеров в OPEN PR с недобором.
+// Пустой teamName - по всем командам. Возвращает PR, в которые кто-то добавлен.
+// PR, который добрать не удалось, пропускается; ошибка - только если прочитать PR не вышло или ctx отменен
+func (s *PullRequestService) BackfillReviewers(ctx context.Context, teamName string) ([]models.PullRequest, error) {
+	prs, err := s.PullRequestServ.GetUnderstaffedPRsTx(ctx, nil, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := []models.PullRequest{}
+	for _, pr := range prs {
+		result, err := s.backfillPR(ctx, pr.PullRequestID)
+		if ctx.Err() != nil {
+			return updated, ctx.Err()
+		}
+		if err != nil {
+			slog.Error("Failed to backfill PR", "pull_request_id", pr.PullRequestID, "error", err)
+			continue
+		}
+		if result != nil {
+			updated = append(updated, *result)
+		}
+	}
+
+	return updated, nil
+}
+
+// backfillPR добирает ревьюеров в один PR; nil, если добавить никого не удалось
+func (s *PullRequestService) backfillPR(ctx context.Context, prID string) (*models.PullRequest, error) {
+	var result *models.PullRequest
+
+	err := s.executeWithRetry(ctx, func() error {
+		result = nil
+
+		tx, err := s.PullRequestServ.PRBeginTx(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+
+		pr, err := s.PullRequestServ.GetPRByIDTx(ctx, tx, prID)
+		if err != nil {
+			return err
+		}
+
+		missing := pr.MissingReviewers()
+		if !pr.IsOpen() || missing <= 0 {
+			return nil
+		}
+
+		team, settings, err := s.prTeamTx(ctx, tx, pr.AuthorID, pr.Repository, pr.TeamName)
+		if err == models.ErrNoTeam {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		q := reviewerQuery{
+			prID:     pr.PullRequestID,
+			authorID: pr.AuthorID,
+			labels:   pr.Labels,
+			exclude:  append([]string{pr.AuthorID}, pr.AssignedReviewers...),
+			trace:    newSelectionTrace(),
+		}
+
+		added, err := s.findReviewersFromTeam(ctx, tx, team, settings, q, missing)
+		if err != nil {
+			return err
+		}
+
+		picks := make([]models.ReviewerPick, 0, missing)
+		for _, userID := range added {
+			picks = append(picks, models.ReviewerPick{UserID: userID, Source: models.ReviewerSourceTeam})
+		}
+
+		var crossTeam []string
+		if left := missing - len(added); left > 0 {
+			crossPicks, err := s.findCrossTeamReviewers(ctx, tx, team, settings, q.without(added...), left)
+			if err != nil {
+				return err
+			}
+			for _, pick := range crossPicks {
+				crossTeam = append(crossTeam, pick.UserID)
+			}
+			added = append(added, crossTeam...)
+			picks = append(picks, crossPicks...)
+		}
+
+		if len(added) == 0 {
+			return nil
+		}
+
+		pr.AssignedReviewers = append(pr.AssignedReviewers, added...)
+		pr.CrossTeamReviewers = append(pr.CrossTeamReviewers, crossTeam...)
+
+		err = s.PullRequestServ.UpdatePRReviewersTx(ctx, tx, pr.PullRequestID, pr.AssignedReviewers, pr.CrossTeamReviewers)
+		if err != nil {
+			return err
+		}
+
+		decisions := q.trace.decisions(pr.PullRequestID, models.AssignmentActionBackfill, "", picks)
+		if err := s.PullRequestServ.CreateAssignmentDecisionsTx(ctx, tx, decisions); err != nil {
+			return err
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return err
+		}
+
+		result = pr
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ReviewerBackfiller добирает ревьюеров в PR с недобором
+type ReviewerBackfiller interface {
+	BackfillReviewers(ctx context.Context, teamName string) ([]models.PullRequest, error)
+}
+
+type ReviewerReconciler struct {
+	backfiller ReviewerBackfiller
+	interval   time.Duration
+	teams      chan string
+}
+
+func NewReviewerReconciler(backfiller ReviewerBackfiller, interval time.Duration) *ReviewerReconciler {
+	return &ReviewerReconciler{
+		backfiller: backfiller,
+		interval:   interval,
+		teams:      make(chan string, 64),
+	}
+}
+
+// TeamCapacityChanged ставит команду в очередь на добор, не блокируя вызывающего.
+// Если очередь переполнена, команда доберется при следующем общем проходе
+func (r *ReviewerReconciler) TeamCapacityChanged(teamName string) {
+	select {
+	case r.teams <- teamName:
+	default:
+		slog.Warn("Reviewer reconciler queue is full, team will wait for the next sweep", "team", teamName)
+	}
+}
+
+// Run обрабатывает очередь команд и периодический проход до отмены ctx
+func (r *ReviewerReconciler) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case teamName := <-r.teams:
+			r.reconcile(ctx, teamName)
+		case <-ticker.C:
+			r.reconcile(ctx, "")
+		}
+	}
+}
+
+func (r *ReviewerReconciler) reconcile(ctx context.Context, teamName string) {
+	updated, err := r.backfiller.BackfillReviewers(ctx, teamName)
+	if err != nil && ctx.Err() == nil {
+		slog.Error("Failed to backfill reviewers", "team", teamName, "error", err)
+	}
+
+	for _, pr := range updated {
+		slog.Info("Backfilled reviewers",
+			"pull_request_id", pr.PullRequestID,
+			"reviewers", pr.AssignedReviewers,
+			"missing", pr.MissingReviewers())
+	}
+}
@@ -0,0 +1,583 @@
+package services
+
+/*
+Выбор ревьюеров (общий для создания PR и переназначения):
+	0. Лид команды, если политика merge требует его одобрения (только при создании PR)
+	1. Владельцы затронутых путей по CODEOWNERS-файлу репозитория или команды автора
+	2. Активные участники команды PR (команда по умолчанию репозитория, иначе команда автора)
+	3. Если команда PR - подкоманда и у нее включен parent_pool: пул родительской команды
+	   (родитель со всеми подкомандами), затем пул ее родителя и так вверх по дереву
+	4. Активные участники запасных команд (teams -> team_fallbacks) по порядку
+	Ревьюеры из шагов 3 и 4 попадают в cross_team_reviewers
+
+На каждом шаге отбрасываются неактивные (is_active = false), исключенные
+(автор, уже назначенные), те, у кого сейчас идет отсутствие (user_absences),
+и те, у кого открытых ревью уже не меньше max_open_reviews команды шага (кроме лида).
+
+Внутри каждого шага порядок задает стратегия команды (см. reviewer_strategy.go),
+по умолчанию - least_loaded. Поверх стратегии выше поднимаются кандидаты,
+чьи навыки (users.skills) покрывают больше меток PR (pull_requests.labels).
+
+Нагрузка и все данные читаются в той же транзакции, что и создание/переназначение.
+
+Если в запросе есть trace, по ходу выбора запоминается, кого отбросили и почему
+и как стратегия упорядочила остальных - это сохраняется в pr_assignment_decisions.
+*/
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"subscription-budget/internal/models"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// reviewerQuery - данные PR, от которых зависит выбор ревьюеров
+type reviewerQuery struct {
+	prID     string
+	authorID string
+	labels   []string
+	exclude  []string
+	trace    *selectionTrace
+}
+
+func (q reviewerQuery) without(userIDs ...string) reviewerQuery {
+	q.exclude = append(append([]string(nil), q.exclude...), userIDs...)
+	return q
+}
+
+// selectionTrace - общий для всех копий reviewerQuery одного выбора
+type selectionTrace struct {
+	excluded []models.CandidateExclusion
+	picked   map[string]models.AssignmentDecision
+}
+
+func newSelectionTrace() *selectionTrace {
+	return &selectionTrace{picked: make(map[string]models.AssignmentDecision)}
+}
+
+func (t *selectionTrace) exclude(userID string, reason string) {
+	if t != nil {
+		t.excluded = append(t.excluded, models.CandidateExclusion{UserID: userID, Reason: reason})
+	}
+}
+
+// takeExcluded отдает исключенных на текущем шаге и начинает следующий шаг
+func (t *selectionTrace) takeExcluded() []models.CandidateExclusion {
+	if t == nil {
+		return nil
+	}
+	excluded := t.excluded
+	t.excluded = nil
+	return excluded
+}
+
+// decisions собирает причины выбора для назначенных ревьюеров
+func (t *selectionTrace) decisions(prID string, action string, replacedUserID string, picks []models.ReviewerPick) []models.AssignmentDecision {
+	decisions := make([]models.AssignmentDecision, 0, len(picks))
+	for _, pick := range picks {
+		decision := t.picked[pick.UserID]
+		decision.PullRequestID = prID
+		decision.ReviewerID = pick.UserID
+		decision.Action = action
+		decision.Rule = pick.Source
+		decision.ReplacedUserID = replacedUserID
+		decisions = append(decisions, decision)
+	}
+	return decisions
+}
+
+// initialReviewers - ревьюеры нового PR (или черновика, переведенного в OPEN)
+type initialReviewers struct {
+	reviewers []string
+	crossTeam []string
+	picks     []models.ReviewerPick
+	trace     *selectionTrace
+}
+
+// selectInitialReviewers выбирает target ревьюеров: лид -> владельцы кода -> команда автора ->
+// родительские команды -> запасные команды
+func (s *PullRequestService) selectInitialReviewers(ctx context.Context, tx pgx.Tx, team *models.Team, settings *models.TeamSettings, req models.CreatePRRequest, labels []string, target int) (*initialReviewers, error) {
+	q := reviewerQuery{
+		prID:     req.PullRequestID,
+		authorID: req.AuthorID,
+		labels:   labels,
+		exclude:  []string{req.AuthorID},
+		trace:    newSelectionTrace(),
+	}
+
+	lead, err := s.findLeadReviewer(ctx, tx, team, settings, q, target)
+	if err != nil {
+		return nil, err
+	}
+
+	owners, err := s.findCodeOwnerReviewers(ctx, tx, settings, req, q.without(lead...), target-len(lead))
+	if err != nil {
+		return nil, err
+	}
+	reviewers := append(append([]string(nil), lead...), owners...)
+
+	teamReviewers, err := s.findReviewersFromTeam(ctx, tx, team, settings, q.without(reviewers...), target-len(reviewers))
+	if err != nil {
+		return nil, err
+	}
+	reviewers = append(reviewers, teamReviewers...)
+
+	var crossTeam []string
+	var crossPicks []models.ReviewerPick
+	if missing := target - len(reviewers); missing > 0 {
+		crossPicks, err = s.findCrossTeamReviewers(ctx, tx, team, settings, q.without(reviewers...), missing)
+		if err != nil {
+			return nil, err
+		}
+		for _, pick := range crossPicks {
+			crossTeam = append(crossTeam, pick.UserID)
+		}
+		reviewers = append(reviewers, crossTeam...)
+	}
+
+	picks := make([]models.ReviewerPick, 0, len(reviewers))
+	for _, userID := range lead {
+		picks = append(picks, models.ReviewerPick{UserID: userID, Source: models.ReviewerSourceTeamLead})
+	}
+	for _, userID := range owners {
+		picks = append(picks, models.ReviewerPick{UserID: userID, Source: models.ReviewerSourceCodeOwner})
+	}
+	for _, userID := range teamReviewers {
+		picks = append(picks, models.ReviewerPick{UserID: userID, Source: models.ReviewerSourceTeam})
+	}
+	picks = append(picks, crossPicks...)
+
+	return &initialReviewers{
+		reviewers: reviewers,
+		crossTeam: crossTeam,
+		picks:     picks,
+		trace:     q.trace,
+	}, nil
+}
+
+// findLeadReviewer назначает лида команды, если политика merge требует его одобрения
+// и он доступен. Для PR самого лида ничего не возвращает
+func (s *PullRequestService) findLeadReviewer(ctx context.Context, tx pgx.Tx, team *models.Team, settings *models.TeamSettings, q reviewerQuery, count int) ([]string, error) {
+	if !settings.RequireLeadApproval || settings.LeadUserID == "" || count <= 0 {
+		return nil, nil
+	}
+
+	var leads []models.User
+	for _, member := range team.Members {
+		if member.UserID == settings.LeadUserID {
+			leads = append(leads, member)
+		}
+	}
+
+	available, err := s.filterAvailable(ctx, tx, leads, q)
+	if err != nil {
+		return nil, err
+	}
+	excluded := q.trace.takeExcluded()
+	if len(available) == 0 {
+		return nil, nil
+	}
+
+	if q.trace != nil {
+		q.trace.picked[settings.LeadUserID] = models.AssignmentDecision{
+			TeamName: settings.TeamName,
+			Considered: []models.ConsideredCandidate{
+				{UserID: settings.LeadUserID, Rank: 1, Selected: true},
+			},
+			Excluded: excluded,
+		}
+	}
+
+	return []string{settings.LeadUserID}, nil
+}
+
+func (s *PullRequestService) findReviewersFromTeam(ctx context.Context, tx pgx.Tx, team *models.Team, settings *models.TeamSettings, q reviewerQuery, count int) ([]string, error) {
+	candidates, err := s.filterAvailable(ctx, tx, team.Members, q)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.pickReviewers(ctx, tx, settings, q, candidates, count)
+}
+
+// findReplacementReviewer ищет замену сначала в команде PR, затем в родительских и запасных командах.
+// Второе значение - взят ли ревьюер из другой команды
+func (s *PullRequestService) findReplacementReviewer(ctx context.Context, tx pgx.Tx, team *models.Team, settings *models.TeamSettings, pr *models.PullRequest, oldUserID string, trace *selectionTrace) (string, bool, error) {
+	q := reviewerQuery{
+		prID:     pr.PullRequestID,
+		authorID: pr.AuthorID,
+		labels:   pr.Labels,
+		exclude:  append([]string{pr.AuthorID, oldUserID}, pr.AssignedReviewers...),
+		trace:    trace,
+	}
+
+	picked, err := s.findReviewersFromTeam(ctx, tx, team, settings, q, 1)
+	if err != nil {
+		return "", false, err
+	}
+	if len(picked) > 0 {
+		return picked[0], false, nil
+	}
+
+	crossPicks, err := s.findCrossTeamReviewers(ctx, tx, team, settings, q, 1)
+	if err != nil {
+		return "", false, err
+	}
+	if len(crossPicks) > 0 {
+		return crossPicks[0].UserID, true, nil
+	}
+
+	return "", false, models.ErrNoCandidate
+}
+
+// findCodeOwnerReviewers выбирает ревьюеров среди владельцев затронутых путей.
+// Файл владения берется у репозитория PR, если его нет - у команды автора
+func (s *PullRequestService) findCodeOwnerReviewers(ctx context.Context, tx pgx.Tx, settings *models.TeamSettings, req models.CreatePRRequest, q reviewerQuery, count int) ([]string, error) {
+	if len(req.ChangedFiles) == 0 || count <= 0 {
+		return nil, nil
+	}
+
+	file, err := s.getOwnershipFile(ctx, tx, req.Repository, settings.TeamName)
+	if err != nil || file == nil {
+		return nil, err
+	}
+
+	owners, err := ParseCodeOwners(file.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	var owned []models.User
+	seen := make(map[string]bool)
+	addCandidate := func(user models.User) {
+		if !seen[user.UserID] {
+			seen[user.UserID] = true
+			owned = append(owned, user)
+		}
+	}
+
+	for _, path := range req.ChangedFiles {
+		for _, owner := range owners.OwnersFor(path) {
+			if owner.TeamName != "" {
+				ownerTeam, err := s.teamStorage.GetTeamInfoTx(ctx, tx, owner.TeamName)
+				if err == models.ErrNotFound {
+					continue
+				}
+				if err != nil {
+					return nil, err
+				}
+				for _, member := range ownerTeam.Members {
+					addCandidate(member)
+				}
+				continue
+			}
+
+			user, err := s.userStorage.GetUserTx(ctx, tx, owner.UserID)
+			if err == models.ErrNotFound {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			addCandidate(*user)
+		}
+	}
+
+	candidates, err := s.filterAvailable(ctx, tx, owned, q)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.pickReviewers(ctx, tx, settings, q, candidates, count)
+}
+
+func (s *PullRequestService) getOwnershipFile(ctx context.Context, tx pgx.Tx, repository string, teamName string) (*models.OwnershipFile, error) {
+	if repository != "" {
+		file, err := s.ownershipStorage.GetOwnershipFileTx(ctx, tx, models.OwnershipScopeRepository, repository)
+		if err == nil {
+			return file, nil
+		}
+		if err != models.ErrNotFound {
+			return nil, err
+		}
+	}
+
+	file, err := s.ownershipStorage.GetOwnershipFileTx(ctx, tx, models.OwnershipScopeTeam, teamName)
+	if err == models.ErrNotFound {
+		return nil, nil
+	}
+	return file, err
+}
+
+// findCrossTeamReviewers добирает ревьюеров вне команды PR: сначала из родительских
+// команд (если у команды включен parent_pool), затем из запасных
+func (s *PullRequestService) findCrossTeamReviewers(ctx context.Context, tx pgx.Tx, team *models.Team, settings *models.TeamSettings, q reviewerQuery, count int) ([]models.ReviewerPick, error) {
+	var picks []models.ReviewerPick
+
+	if settings.ParentPool {
+		fromParents, err := s.findParentReviewers(ctx, tx, team, q, count)
+		if err != nil {
+			return nil, err
+		}
+		for _, userID := range fromParents {
+			picks = append(picks, models.ReviewerPick{UserID: userID, Source: models.ReviewerSourceParent})
+		}
+		q = q.without(fromParents...)
+	}
+
+	if missing := count - len(picks); missing > 0 {
+		fromFallbacks, err := s.findFallbackReviewers(ctx, tx, settings, q, missing)
+		if err != nil {
+			return nil, err
+		}
+		for _, userID := range fromFallbacks {
+			picks = append(picks, models.ReviewerPick{UserID: userID, Source: models.ReviewerSourceFallback})
+		}
+	}
+
+	return picks, nil
+}
+
+// findParentReviewers поднимается по родителям команды PR и добирает ревьюеров из пула
+// каждого (родитель со всеми подкомандами). Порядок задает стратегия родителя;
+// участники, уже рассмотренные на предыдущих шагах, повторно не рассматриваются
+func (s *PullRequestService) findParentReviewers(ctx context.Context, tx pgx.Tx, team *models.Team, q reviewerQuery, count int) ([]string, error) {
+	ancestors, err := s.teamStorage.GetAncestorTeamsTx(ctx, tx, team.TeamName)
+	if err != nil || len(ancestors) == 0 {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(team.Members))
+	for _, member := range team.Members {
+		seen[member.UserID] = true
+	}
+
+	var picked []string
+	for _, parentName := range ancestors {
+		if len(picked) >= count {
+			break
+		}
+
+		members, err := s.teamStorage.GetPoolMembersTx(ctx, tx, parentName)
+		if err != nil {
+			return nil, err
+		}
+
+		pool := &models.Team{TeamName: parentName}
+		for _, member := range members {
+			if !seen[member.UserID] {
+				seen[member.UserID] = true
+				pool.Members = append(pool.Members, member)
+			}
+		}
+
+		parentSettings, err := s.teamStorage.GetTeamSettingsTx(ctx, tx, parentName)
+		if err == models.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		fromPool, err := s.findReviewersFromTeam(ctx, tx, pool, parentSettings, q.without(picked...), count-len(picked))
+		if err != nil {
+			return nil, err
+		}
+		picked = append(picked, fromPool...)
+	}
+
+	return picked, nil
+}
+
+// findFallbackReviewers добирает ревьюеров из запасных команд в порядке их приоритета.
+// Внутри запасной команды порядок задает ее собственная стратегия
+func (s *PullRequestService) findFallbackReviewers(ctx context.Context, tx pgx.Tx, settings *models.TeamSettings, q reviewerQuery, count int) ([]string, error) {
+	var picked []string
+
+	for _, fallbackName := range settings.FallbackTeams {
+		if len(picked) >= count {
+			break
+		}
+
+		fallbackTeam, err := s.teamStorage.GetTeamInfoTx(ctx, tx, fallbackName)
+		if err == models.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		fallbackSettings, err := s.teamStorage.GetTeamSettingsTx(ctx, tx, fallbackName)
+		if err != nil {
+			return nil, err
+		}
+
+		fromTeam, err := s.findReviewersFromTeam(ctx, tx, fallbackTeam, fallbackSettings, q.without(picked...), count-len(picked))
+		if err != nil {
+			return nil, err
+		}
+		picked = append(picked, fromTeam...)
+	}
+
+	return picked, nil
+}
+
+// filterAvailable оставляет активных, не исключенных и не отсутствующих
+// прямо сейчас (user_absences) пользователей
+func (s *PullRequestService) filterAvailable(ctx context.Context, tx pgx.Tx, users []models.User, q reviewerQuery) ([]models.User, error) {
+	var candidates []models.User
+	var userIDs []string
+	for _, user := range users {
+		switch {
+		case user.UserID == q.authorID:
+			q.trace.exclude(user.UserID, models.ExclusionAuthor)
+		case contains(q.exclude, user.UserID):
+			q.trace.exclude(user.UserID, models.ExclusionAlreadyAssigned)
+		case !user.IsActive:
+			q.trace.exclude(user.UserID, models.ExclusionInactive)
+		default:
+			candidates = append(candidates, user)
+			userIDs = append(userIDs, user.UserID)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	absent, err := s.userStorage.GetAbsentUsersTx(ctx, tx, userIDs, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	available := candidates[:0]
+	for _, user := range candidates {
+		if absent[user.UserID] {
+			q.trace.exclude(user.UserID, models.ExclusionAbsent)
+			continue
+		}
+		available = append(available, user)
+	}
+	return available, nil
+}
+
+// pickReviewers отбрасывает кандидатов сверх лимита нагрузки, упорядочивает остальных
+// стратегией команды, поднимает выше подходящих по навыкам и берет первых count
+func (s *PullRequestService) pickReviewers(ctx context.Context, tx pgx.Tx, settings *models.TeamSettings, q reviewerQuery, users []models.User, count int) ([]string, error) {
+	if len(users) == 0 || count <= 0 {
+		q.trace.takeExcluded()
+		return nil, nil
+	}
+
+	userIDs := make([]string, len(users))
+	for i, user := range users {
+		userIDs[i] = user.UserID
+	}
+
+	load, err := s.PullRequestServ.GetOpenReviewLoadTx(ctx, tx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	users = withinCapacity(users, load, settings.MaxOpenReviews, q.trace)
+	excluded := q.trace.takeExcluded()
+	if len(users) == 0 {
+		return nil, nil
+	}
+
+	strategy, err := s.strategies.Get(settings.ReviewerStrategy)
+	if err != nil {
+		slog.Warn("Unknown reviewer strategy, falling back to least_loaded",
+			"team", settings.TeamName, "strategy", settings.ReviewerStrategy)
+		strategy = LeastLoadedStrategy{}
+	}
+
+	candidates := make([]ReviewCandidate, len(users))
+	coverage := make(map[string]int, len(users))
+	for i, user := range users {
+		candidates[i] = ReviewCandidate{
+			UserID:      user.UserID,
+			OpenReviews: load[user.UserID],
+			Skills:      user.Skills,
+		}
+		coverage[user.UserID] = skillCoverage(user.Skills, q.labels)
+	}
+
+	ordered := strategy.Order(StrategyInput{
+		PullRequestID: q.prID,
+		Labels:        q.labels,
+		Candidates:    candidates,
+		Cursor:        settings.RoundRobinCursor,
+		Seed:          settings.ReviewerSeed,
+	})
+
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return coverage[ordered[i]] > coverage[ordered[j]]
+	})
+	picked := ordered
+	if len(picked) > count {
+		picked = picked[:count]
+	}
+
+	if q.trace != nil {
+		considered := make([]models.ConsideredCandidate, len(ordered))
+		for i, userID := range ordered {
+			considered[i] = models.ConsideredCandidate{
+				UserID:       userID,
+				OpenReviews:  load[userID],
+				SkillMatches: coverage[userID],
+				Rank:         i + 1,
+				Selected:     i < len(picked),
+			}
+		}
+		for _, userID := range picked {
+			q.trace.picked[userID] = models.AssignmentDecision{
+				TeamName:   settings.TeamName,
+				Strategy:   strategy.Name(),
+				Considered: considered,
+				Excluded:   excluded,
+			}
+		}
+	}
+
+	if strategy.Name() == models.StrategyRoundRobin && len(picked) > 0 {
+		err = s.teamStorage.SetRoundRobinCursorTx(ctx, tx, settings.TeamName, picked[len(picked)-1])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return picked, nil
+}
+
+// withinCapacity оставляет пользователей, у которых открытых ревью меньше maxOpen
+// (0 - без ограничения); остальные попадают в trace как over_capacity
+func withinCapacity(users []models.User, load map[string]int, maxOpen int, trace *selectionTrace) []models.User {
+	if maxOpen <= 0 {
+		return users
+	}
+
+	var within []models.User
+	for _, user := range users {
+		if load[user.UserID] >= maxOpen {
+			trace.exclude(user.UserID, models.ExclusionOverCapacity)
+			continue
+		}
+		within = append(within, user)
+	}
+	return within
+}
+
+// skillCoverage - сколько меток PR покрыто навыками пользователя
+func skillCoverage(skills []string, labels []string) int {
+	covered := 0
+	for _, label := range labels {
+		if contains(skills, label) {
+			covered++
+		}
+	}
+	return covered
+}
@@ -0,0 +1,65 @@
+package services
+
+/*
+Проверка лимита нагрузки ревьюеров (withinCapacity):
+	1. Без лимита остаются все
+	2. Кандидаты с нагрузкой не меньше лимита отбрасываются как over_capacity
+	3. Без trace фильтр работает так же
+*/
+import (
+	"subscription-budget/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithinCapacity(t *testing.T) {
+	users := []models.User{{UserID: "u1"}, {UserID: "u2"}, {UserID: "u3"}}
+	load := map[string]int{"u1": 0, "u2": 3, "u3": 2}
+
+	tests := []struct {
+		name         string
+		maxOpen      int
+		wantUsers    []string
+		wantExcluded []models.CandidateExclusion
+	}{
+		{
+			name:      "no limit",
+			maxOpen:   0,
+			wantUsers: []string{"u1", "u2", "u3"},
+		},
+		{
+			name:      "limit at load is over capacity",
+			maxOpen:   3,
+			wantUsers: []string{"u1", "u3"},
+			wantExcluded: []models.CandidateExclusion{
+				{UserID: "u2", Reason: models.ExclusionOverCapacity},
+			},
+		},
+		{
+			name:      "only idle reviewer left",
+			maxOpen:   1,
+			wantUsers: []string{"u1"},
+			wantExcluded: []models.CandidateExclusion{
+				{UserID: "u2", Reason: models.ExclusionOverCapacity},
+				{UserID: "u3", Reason: models.ExclusionOverCapacity},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trace := newSelectionTrace()
+			within := withinCapacity(users, load, tt.maxOpen, trace)
+
+			ids := make([]string, len(within))
+			for i, user := range within {
+				ids[i] = user.UserID
+			}
+			assert.Equal(t, tt.wantUsers, ids)
+			assert.Equal(t, tt.wantExcluded, trace.takeExcluded())
+
+			assert.Len(t, withinCapacity(users, load, tt.maxOpen, nil), len(tt.wantUsers))
+		})
+	}
+}
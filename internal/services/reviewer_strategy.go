@@ -0,0 +1,143 @@
+package services
+
+/*
+Стратегии выбора ревьюеров:
+	1. first_n - первые по user_id
+	2. round_robin - по кругу, начиная после последнего назначенного
+	3. least_loaded - с наименьшим числом OPEN ревью (по умолчанию)
+	4. seeded_random - детерминированный случайный порядок (seed команды + id PR)
+
+Стратегия только упорядочивает уже отфильтрованных кандидатов,
+сколько из них взять - решает сервис.
+Какую стратегию использует команда - хранится в teams.reviewer_strategy.
+Свою стратегию можно добавить через StrategyRegistry.Register
+*/
+
+import (
+	"hash/fnv"
+	"math/rand/v2"
+	"sort"
+	"subscription-budget/internal/models"
+	"sync"
+)
+
+type ReviewCandidate struct {
+	UserID      string
+	OpenReviews int
+	Skills      []string
+}
+
+type StrategyInput struct {
+	PullRequestID string
+	Labels        []string
+	Candidates    []ReviewCandidate
+	Cursor        string
+	Seed          int64
+}
+
+type ReviewerStrategy interface {
+	Name() string
+	Order(in StrategyInput) []string
+}
+
+type StrategyRegistry struct {
+	mu         sync.RWMutex
+	strategies map[string]ReviewerStrategy
+}
+
+func NewStrategyRegistry() *StrategyRegistry {
+	r := &StrategyRegistry{
+		strategies: make(map[string]ReviewerStrategy),
+	}
+	r.Register(FirstNStrategy{})
+	r.Register(RoundRobinStrategy{})
+	r.Register(LeastLoadedStrategy{})
+	r.Register(SeededRandomStrategy{})
+	return r
+}
+
+func (r *StrategyRegistry) Register(strategy ReviewerStrategy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.strategies[strategy.Name()] = strategy
+}
+
+func (r *StrategyRegistry) Get(name string) (ReviewerStrategy, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	strategy, ok := r.strategies[name]
+	if !ok {
+		return nil, models.ErrUnknownStrategy
+	}
+	return strategy, nil
+}
+
+type FirstNStrategy struct{}
+
+func (FirstNStrategy) Name() string { return models.StrategyFirstN }
+
+func (FirstNStrategy) Order(in StrategyInput) []string {
+	return sortedCandidateIDs(in.Candidates)
+}
+
+type RoundRobinStrategy struct{}
+
+func (RoundRobinStrategy) Name() string { return models.StrategyRoundRobin }
+
+func (RoundRobinStrategy) Order(in StrategyInput) []string {
+	ids := sortedCandidateIDs(in.Candidates)
+	start := sort.SearchStrings(ids, in.Cursor)
+	if start < len(ids) && ids[start] == in.Cursor {
+		start++
+	}
+	if start >= len(ids) {
+		start = 0
+	}
+	return append(ids[start:], ids[:start]...)
+}
+
+type LeastLoadedStrategy struct{}
+
+func (LeastLoadedStrategy) Name() string { return models.StrategyLeastLoaded }
+
+func (LeastLoadedStrategy) Order(in StrategyInput) []string {
+	candidates := append([]ReviewCandidate(nil), in.Candidates...)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].OpenReviews != candidates[j].OpenReviews {
+			return candidates[i].OpenReviews < candidates[j].OpenReviews
+		}
+		return candidates[i].UserID < candidates[j].UserID
+	})
+
+	ids := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		ids[i] = candidate.UserID
+	}
+	return ids
+}
+
+type SeededRandomStrategy struct{}
+
+func (SeededRandomStrategy) Name() string { return models.StrategySeededRandom }
+
+func (SeededRandomStrategy) Order(in StrategyInput) []string {
+	ids := sortedCandidateIDs(in.Candidates)
+
+	h := fnv.New64a()
+	h.Write([]byte(in.PullRequestID))
+	rnd := rand.New(rand.NewPCG(uint64(in.Seed), h.Sum64()))
+	rnd.Shuffle(len(ids), func(i, j int) {
+		ids[i], ids[j] = ids[j], ids[i]
+	})
+	return ids
+}
+
+func sortedCandidateIDs(candidates []ReviewCandidate) []string {
+	ids := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		ids[i] = candidate.UserID
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -3,20 +3,74 @@ package services
 import (
 	"context"
 	"subscription-budget/internal/models"
+
+	"github.com/jackc/pgx/v5"
 )
 
 type TeamManager interface {
 	CreateTeam(ctx context.Context, team models.Team) (*models.Team, error)
-	GetTeam(ctx context.Context, teamName string) (*models.Team, error)
+	GetTeam(ctx context.Context, teamName string, includeChildren bool) (*models.Team, error)
+	UpdateTeam(ctx context.Context, update models.TeamUpdate) (*models.Team, error)
+	GetTeamSettings(ctx context.Context, teamName string) (*models.TeamSettings, error)
+	UpdateTeamSettings(ctx context.Context, update models.TeamSettingsUpdate) (*models.TeamSettings, error)
+	AddMember(ctx context.Context, req models.AddMemberRequest) (*models.Team, error)
+	RemoveMember(ctx context.Context, req models.RemoveMemberRequest) ([]models.ReviewReassignment, error)
+	MoveMember(ctx context.Context, req models.MoveMemberRequest) (*models.Team, []models.ReviewReassignment, error)
+	ArchiveTeam(ctx context.Context, req models.ArchiveTeamRequest) (*models.TeamArchivePlan, error)
+	SetPrimaryTeam(ctx context.Context, req models.SetPrimaryTeamRequest) ([]models.TeamMembership, error)
 }
 
 type UserManager interface {
-	SetUserActive(ctx context.Context, userID string, isActive bool) (*models.User, error)
+	SetUserActive(ctx context.Context, userID string, isActive bool, reassignReviews bool) (*models.User, []models.ReviewReassignment, error)
+	SetUserSkills(ctx context.Context, userID string, skills []string) (*models.User, error)
+	AddAbsence(ctx context.Context, absence models.UserAbsence) (*models.UserAbsence, error)
+	GetAbsences(ctx context.Context, userID string) ([]models.UserAbsence, error)
+	DeleteAbsence(ctx context.Context, absenceID int64) error
 }
 
 type PullRequestManager interface {
 	CreatePR(ctx context.Context, req models.CreatePRRequest) (*models.PullRequest, error)
-	MergePR(ctx context.Context, prID string) (*models.PullRequest, error)
+	PreviewPR(ctx context.Context, req models.CreatePRRequest) (*models.PRPreview, error)
+	GetPR(ctx context.Context, prID string, explain bool) (*models.PullRequest, []models.AssignmentDecision, error)
+	UpdatePR(ctx context.Context, req models.UpdatePRRequest) (*models.PullRequest, error)
+	MergePR(ctx context.Context, req models.MergePRRequest) (*models.PullRequest, error)
+	SetDependencies(ctx context.Context, req models.SetDependenciesRequest) (*models.PullRequest, error)
+	GetPRStack(ctx context.Context, prID string) ([]models.PullRequest, error)
+	ReadyForReview(ctx context.Context, req models.ReadyPRRequest) (*models.PullRequest, error)
+	ClosePR(ctx context.Context, prID string) (*models.PullRequest, error)
+	ReopenPR(ctx context.Context, prID string) (*models.PullRequest, error)
 	ReassignReviewer(ctx context.Context, req models.ReassignRequest) (*models.PullRequest, string, error)
-	GetUserReviews(ctx context.Context, userID string) ([]models.PullRequestShort, error)
+	GetUserReviews(ctx context.Context, userID string, repository string) ([]models.PullRequestShort, error)
+	GetPRHistory(ctx context.Context, prID string) ([]models.PRHistoryEntry, error)
+	SubmitReview(ctx context.Context, req models.SubmitReviewRequest) (*models.PullRequestReview, error)
+	RerequestReview(ctx context.Context, prID string, reviewerID string) (*models.PullRequestReview, error)
+	AddComment(ctx context.Context, req models.AddCommentRequest) (*models.CommentThread, error)
+	ResolveThread(ctx context.Context, req models.ResolveThreadRequest) (*models.CommentThread, error)
+	UnresolveThread(ctx context.Context, req models.ResolveThreadRequest) (*models.CommentThread, error)
+	GetCommentThreads(ctx context.Context, prID string) ([]models.CommentThread, error)
+	GetStalePRs(ctx context.Context, teamName string) ([]models.StalePR, error)
+}
+
+type RepositoryManager interface {
+	CreateRepository(ctx context.Context, repo models.Repository) (*models.Repository, error)
+	GetRepository(ctx context.Context, name string) (*models.Repository, error)
+	UpdateRepositorySettings(ctx context.Context, update models.RepositorySettingsUpdate) (*models.Repository, error)
+}
+
+type OwnershipManager interface {
+	UploadOwnershipFile(ctx context.Context, file models.OwnershipFile) (*models.OwnershipFile, error)
+	GetOwnershipFile(ctx context.Context, scope string, scopeRef string) (*models.OwnershipFile, error)
+}
+
+// ReviewReassigner находит и снимает OPEN ревью пользователя в чужой транзакции.
+// Пустой teamName - ревью во всех PR, иначе только в PR этой команды
+type ReviewReassigner interface {
+	OpenReviewsTx(ctx context.Context, tx pgx.Tx, userID string, teamName string) ([]string, error)
+	ReassignOpenReviewsTx(ctx context.Context, tx pgx.Tx, userID string, teamName string) ([]models.ReviewReassignment, error)
+	ReassignReviewsTx(ctx context.Context, tx pgx.Tx, userID string, prIDs []string) ([]models.ReviewReassignment, error)
+}
+
+// CapacityNotifier получает сигнал, что в команде могли появиться свободные ревьюеры
+type CapacityNotifier interface {
+	TeamCapacityChanged(teamName string)
 }
@@ -0,0 +1,211 @@
+package services
+
+/*
+Устаревшие PR - команда включает проверку (stale_enabled) и задает пороги:
+	1. SweepStalePRs - проходит по открытым PR таких команд:
+	   без активности stale_after_days дней - PR помечается устаревшим (событие stale),
+	   появилась активность - отметка снимается (stale_cleared),
+	   устаревший PR без активности еще stale_close_after_days дней - закрывается (closed)
+	2. GetStalePRs - устаревшие PR команды
+	3. StalePRWorker - фоновый воркер внутри приложения, проходит раз в interval
+
+Активность - создание PR, события истории (кроме системных), отправленные ревью и комментарии.
+Каждый PR обрабатывается в своей сериализуемой транзакции с повторами: активность и отметка
+перечитываются в ней же, так что ревью или комментарий, пришедшие после общего списка,
+не дадут закрыть PR. Ошибка в одном PR пишется в лог и не останавливает проход.
+*/
+
+import (
+	"context"
+	"log/slog"
+	"subscription-budget/internal/models"
+	"time"
+)
+
+// SweepStalePRs помечает, снимает отметку или закрывает устаревшие PR на момент now.
+// Возвращает записи истории, сделанные за этот проход; ошибка - только если прочитать
+// PR не вышло или ctx отменен
+func (s *PullRequestService) SweepStalePRs(ctx context.Context, now time.Time) ([]models.PRHistoryEntry, error) {
+	candidates, err := s.PullRequestServ.GetStaleCandidatesTx(ctx, nil, "")
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []models.PRHistoryEntry{}
+	for _, candidate := range candidates {
+		entry, err := s.sweepStalePR(ctx, candidate.PullRequestID, now)
+		if ctx.Err() != nil {
+			return entries, ctx.Err()
+		}
+		if err != nil {
+			slog.Error("Failed to sweep stale PR", "pull_request_id", candidate.PullRequestID, "error", err)
+			continue
+		}
+		if entry != nil {
+			entries = append(entries, *entry)
+		}
+	}
+
+	return entries, nil
+}
+
+// sweepStalePR обрабатывает один PR; nil, если менять ничего не нужно
+func (s *PullRequestService) sweepStalePR(ctx context.Context, prID string, now time.Time) (*models.PRHistoryEntry, error) {
+	var result *models.PRHistoryEntry
+
+	err := s.executeWithRetry(ctx, func() error {
+		result = nil
+
+		tx, err := s.PullRequestServ.PRBeginTx(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+
+		pr, err := s.PullRequestServ.GetPRByIDTx(ctx, tx, prID)
+		if err != nil {
+			return err
+		}
+
+		// PR уже не открыт или проверку у команды выключили
+		candidate, err := s.PullRequestServ.GetStaleCandidateTx(ctx, tx, prID)
+		if err == models.ErrNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		entry := models.PRHistoryEntry{
+			PullRequestID: pr.PullRequestID,
+			FromStatus:    pr.Status,
+			ToStatus:      pr.Status,
+			Details: map[string]any{
+				"last_activity_at": candidate.LastActivityAt,
+			},
+		}
+
+		staleSince := candidate.LastActivityAt.AddDate(0, 0, candidate.StaleAfterDays)
+
+		switch {
+		case candidate.StaleAt != nil && !candidate.IsStale():
+			if err := s.PullRequestServ.SetPRStaleTx(ctx, tx, pr.PullRequestID, nil); err != nil {
+				return err
+			}
+			entry.Event = models.PREventStaleCleared
+
+		case candidate.StaleAt == nil && !now.Before(staleSince):
+			if err := s.PullRequestServ.SetPRStaleTx(ctx, tx, pr.PullRequestID, &now); err != nil {
+				return err
+			}
+			entry.Event = models.PREventStale
+			entry.Details["stale_after_days"] = candidate.StaleAfterDays
+
+		case candidate.StaleAt != nil && candidate.StaleCloseAfterDays > 0 &&
+			!now.Before(candidate.StaleAt.AddDate(0, 0, candidate.StaleCloseAfterDays)):
+			if !models.CanTransition(pr.Status, models.PRStatusClosed) {
+				return nil
+			}
+			err := s.PullRequestServ.UpdatePRStatusTx(ctx, tx, pr.PullRequestID, pr.Status, models.PRStatusClosed)
+			if err != nil {
+				return err
+			}
+			entry.Event = models.PREventClosed
+			entry.ToStatus = models.PRStatusClosed
+			entry.Details["reason"] = "stale"
+			entry.Details["stale_at"] = candidate.StaleAt
+
+		default:
+			return nil
+		}
+
+		if err := s.PullRequestServ.AddPRHistoryTx(ctx, tx, entry); err != nil {
+			return err
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return err
+		}
+
+		result = &entry
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetStalePRs - устаревшие PR команды. Пустой список, если проверка у команды выключена
+func (s *PullRequestService) GetStalePRs(ctx context.Context, teamName string) ([]models.StalePR, error) {
+	if _, err := s.teamStorage.GetTeamSettingsTx(ctx, nil, teamName); err != nil {
+		return nil, err
+	}
+
+	candidates, err := s.PullRequestServ.GetStaleCandidatesTx(ctx, nil, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	stale := []models.StalePR{}
+	for _, pr := range candidates {
+		if !pr.IsStale() {
+			continue
+		}
+		if pr.StaleCloseAfterDays > 0 {
+			closeAt := pr.StaleAt.AddDate(0, 0, pr.StaleCloseAfterDays)
+			pr.CloseAt = &closeAt
+		}
+		stale = append(stale, pr)
+	}
+
+	return stale, nil
+}
+
+// StaleSweeper помечает и закрывает устаревшие PR
+type StaleSweeper interface {
+	SweepStalePRs(ctx context.Context, now time.Time) ([]models.PRHistoryEntry, error)
+}
+
+type StalePRWorker struct {
+	sweeper  StaleSweeper
+	interval time.Duration
+}
+
+func NewStalePRWorker(sweeper StaleSweeper, interval time.Duration) *StalePRWorker {
+	return &StalePRWorker{
+		sweeper:  sweeper,
+		interval: interval,
+	}
+}
+
+// Run проверяет PR раз в interval до отмены ctx
+func (w *StalePRWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.sweep(ctx)
+		}
+	}
+}
+
+func (w *StalePRWorker) sweep(ctx context.Context) {
+	entries, err := w.sweeper.SweepStalePRs(ctx, time.Now())
+	if err != nil && ctx.Err() == nil {
+		slog.Error("Failed to sweep stale PRs", "error", err)
+	}
+
+	for _, entry := range entries {
+		slog.Info("Stale PR check",
+			"pull_request_id", entry.PullRequestID,
+			"event", entry.Event,
+			"status", entry.ToStatus)
+	}
+}
@@ -2,8 +2,15 @@ package services
 
 /*
 Функции:
-	1. Создание команды
-	2. Получение информации о комнаде
+	1. Создание команды (участники вступают в команду - запускается добор
+	   ревьюеров в ее PR с недобором, см. reviewer_reconciler.go)
+	2. Получение информации о комнаде и изменение ее метаданных (описание, лид, чат,
+	   политика ревью, родительская команда). Команда может быть без участников.
+	   С includeChildren команда отдается вместе с деревом подкоманд
+	   Участники (добавить, убрать, перевести в другую команду) - team_members.go
+	3. Получение и изменение настроек команды (стратегия и число ревьюеров,
+	   запасные команды для поиска ревьюеров, политика merge).
+	   Лид должен состоять в команде, а обязательное одобрение лида - требует лида
 
 Фича - указываем в GetTeamInfoTx nil вместо индекса, он автоматом выполняется через
 пул
@@ -13,15 +20,23 @@ import (
 	"subscription-budget/internal/models"
 	"subscription-budget/internal/storage"
 	"time"
+
+	"github.com/jackc/pgx/v5"
 )
 
 type TeamService struct {
-	storage storage.TeamStorage
+	storage    storage.TeamStorage
+	strategies *StrategyRegistry
+	reassigner ReviewReassigner
+	notifier   CapacityNotifier
 }
 
-func NewTeamService(storage storage.TeamStorage) *TeamService {
+func NewTeamService(storage storage.TeamStorage, strategies *StrategyRegistry, reassigner ReviewReassigner, notifier CapacityNotifier) *TeamService {
 	return &TeamService{
-		storage: storage,
+		storage:    storage,
+		strategies: strategies,
+		reassigner: reassigner,
+		notifier:   notifier,
 	}
 }
 
@@ -50,6 +65,17 @@ func (s *TeamService) executeWithRetryTeam(ctx context.Context, operation func()
 }
 
 func (s *TeamService) CreateTeam(ctx context.Context, team models.Team) (*models.Team, error) {
+	leadIsMember := team.LeadUserID == ""
+	for i := range team.Members {
+		team.Members[i].Skills = normalizeTags(team.Members[i].Skills)
+		if team.Members[i].UserID == team.LeadUserID {
+			leadIsMember = true
+		}
+	}
+	if !leadIsMember {
+		return nil, models.ErrInvalidLead
+	}
+
 	var result *models.Team
 
 	err := s.executeWithRetryTeam(ctx, func() error {
@@ -59,6 +85,12 @@ func (s *TeamService) CreateTeam(ctx context.Context, team models.Team) (*models
 		}
 		defer tx.Rollback(ctx)
 
+		if team.ParentName != "" {
+			if err := s.validateParentTx(ctx, tx, team.TeamName, team.ParentName); err != nil {
+				return err
+			}
+		}
+
 		err = s.storage.CreateTeamTx(ctx, tx, team)
 		if err != nil {
 			return err
@@ -81,10 +113,14 @@ func (s *TeamService) CreateTeam(ctx context.Context, team models.Team) (*models
 		return nil, err
 	}
 
+	if s.notifier != nil {
+		s.notifier.TeamCapacityChanged(result.TeamName)
+	}
+
 	return result, nil
 }
 
-func (s *TeamService) GetTeam(ctx context.Context, teamName string) (*models.Team, error) {
+func (s *TeamService) GetTeam(ctx context.Context, teamName string, includeChildren bool) (*models.Team, error) {
 	var result *models.Team
 
 	err := s.executeWithRetryTeam(ctx, func() error {
@@ -93,6 +129,110 @@ func (s *TeamService) GetTeam(ctx context.Context, teamName string) (*models.Tea
 			return err
 		}
 
+		if includeChildren {
+			if err := s.loadChildren(ctx, team, map[string]bool{team.TeamName: true}); err != nil {
+				return err
+			}
+		}
+
+		result = team
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// loadChildren заполняет дерево подкоманд team; seen защищает от циклов
+func (s *TeamService) loadChildren(ctx context.Context, team *models.Team, seen map[string]bool) error {
+	children, err := s.storage.GetChildTeamsTx(ctx, nil, team.TeamName)
+	if err != nil {
+		return err
+	}
+
+	team.Children = []models.Team{}
+	for _, childName := range children {
+		if seen[childName] {
+			continue
+		}
+		seen[childName] = true
+
+		child, err := s.storage.GetTeamInfoTx(ctx, nil, childName)
+		if err == models.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := s.loadChildren(ctx, child, seen); err != nil {
+			return err
+		}
+		team.Children = append(team.Children, *child)
+	}
+	return nil
+}
+
+// UpdateTeam меняет метаданные команды. Лид должен быть участником; снять лида нельзя,
+// пока политика merge требует его одобрения. Родитель - активная команда не из своего поддерева
+func (s *TeamService) UpdateTeam(ctx context.Context, update models.TeamUpdate) (*models.Team, error) {
+	var result *models.Team
+
+	err := s.executeWithRetryTeam(ctx, func() error {
+		tx, err := s.storage.TeamBeginTx(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+
+		team, err := s.storage.GetTeamInfoTx(ctx, tx, update.TeamName)
+		if err != nil {
+			return err
+		}
+
+		if update.Description != nil {
+			team.Description = *update.Description
+		}
+		if update.ChatChannel != nil {
+			team.ChatChannel = *update.ChatChannel
+		}
+		if update.ReviewPolicy != nil {
+			team.ReviewPolicy = *update.ReviewPolicy
+		}
+		if update.ParentName != nil {
+			if *update.ParentName != "" {
+				if err := s.validateParentTx(ctx, tx, team.TeamName, *update.ParentName); err != nil {
+					return err
+				}
+			}
+			team.ParentName = *update.ParentName
+		}
+		if update.LeadUserID != nil {
+			settings, err := s.storage.GetTeamSettingsTx(ctx, tx, update.TeamName)
+			if err != nil {
+				return err
+			}
+			settings.LeadUserID = *update.LeadUserID
+			err = s.validateMergePolicy(ctx, tx, settings)
+			if err == models.ErrInvalidMergePolicy {
+				return models.ErrInvalidLead
+			}
+			if err != nil {
+				return err
+			}
+			team.LeadUserID = *update.LeadUserID
+		}
+
+		if err := s.storage.UpdateTeamInfoTx(ctx, tx, *team); err != nil {
+			return err
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return err
+		}
+
 		result = team
 		return nil
 	})
@@ -103,3 +243,185 @@ func (s *TeamService) GetTeam(ctx context.Context, teamName string) (*models.Tea
 
 	return result, nil
 }
+
+func (s *TeamService) GetTeamSettings(ctx context.Context, teamName string) (*models.TeamSettings, error) {
+	var result *models.TeamSettings
+
+	err := s.executeWithRetryTeam(ctx, func() error {
+		settings, err := s.storage.GetTeamSettingsTx(ctx, nil, teamName)
+		if err != nil {
+			return err
+		}
+
+		result = settings
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *TeamService) UpdateTeamSettings(ctx context.Context, update models.TeamSettingsUpdate) (*models.TeamSettings, error) {
+	if update.ReviewerStrategy != nil {
+		if _, err := s.strategies.Get(*update.ReviewerStrategy); err != nil {
+			return nil, err
+		}
+	}
+
+	var result *models.TeamSettings
+
+	err := s.executeWithRetryTeam(ctx, func() error {
+		tx, err := s.storage.TeamBeginTx(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+
+		settings, err := s.storage.GetTeamSettingsTx(ctx, tx, update.TeamName)
+		if err != nil {
+			return err
+		}
+
+		if update.ReviewerStrategy != nil {
+			settings.ReviewerStrategy = *update.ReviewerStrategy
+		}
+		if update.ReviewerSeed != nil {
+			settings.ReviewerSeed = *update.ReviewerSeed
+		}
+		if update.ReviewerCount != nil {
+			settings.ReviewerCount = *update.ReviewerCount
+		}
+		if update.FallbackTeams != nil {
+			if err := s.validateFallbackTeams(ctx, tx, update.TeamName, *update.FallbackTeams); err != nil {
+				return err
+			}
+			settings.FallbackTeams = *update.FallbackTeams
+		}
+		if update.ParentPool != nil {
+			settings.ParentPool = *update.ParentPool
+		}
+		if update.MaxOpenReviews != nil {
+			settings.MaxOpenReviews = *update.MaxOpenReviews
+		}
+		if update.MinApprovals != nil {
+			settings.MinApprovals = *update.MinApprovals
+		}
+		if update.BlockOnChangesRequested != nil {
+			settings.BlockOnChangesRequested = *update.BlockOnChangesRequested
+		}
+		if update.RequireLeadApproval != nil {
+			settings.RequireLeadApproval = *update.RequireLeadApproval
+		}
+		if update.LeadUserID != nil {
+			settings.LeadUserID = *update.LeadUserID
+		}
+		if update.RequireResolvedThreads != nil {
+			settings.RequireResolvedThreads = *update.RequireResolvedThreads
+		}
+		if update.ReviewSLAHours != nil {
+			settings.ReviewSLAHours = *update.ReviewSLAHours
+		}
+		if update.SLAReassign != nil {
+			settings.SLAReassign = *update.SLAReassign
+		}
+		if update.StaleEnabled != nil {
+			settings.StaleEnabled = *update.StaleEnabled
+		}
+		if update.StaleAfterDays != nil {
+			settings.StaleAfterDays = *update.StaleAfterDays
+		}
+		if update.StaleCloseAfterDays != nil {
+			settings.StaleCloseAfterDays = *update.StaleCloseAfterDays
+		}
+		if err := s.validateMergePolicy(ctx, tx, settings); err != nil {
+			return err
+		}
+
+		err = s.storage.UpdateTeamSettingsTx(ctx, tx, *settings)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return err
+		}
+
+		result = settings
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *TeamService) validateMergePolicy(ctx context.Context, tx pgx.Tx, settings *models.TeamSettings) error {
+	if settings.MinApprovals < 0 {
+		return models.ErrInvalidMergePolicy
+	}
+
+	if settings.LeadUserID == "" {
+		if settings.RequireLeadApproval {
+			return models.ErrInvalidMergePolicy
+		}
+		return nil
+	}
+
+	team, err := s.storage.GetTeamInfoTx(ctx, tx, settings.TeamName)
+	if err != nil {
+		return err
+	}
+	for _, member := range team.Members {
+		if member.UserID == settings.LeadUserID {
+			return nil
+		}
+	}
+	return models.ErrInvalidMergePolicy
+}
+
+func (s *TeamService) validateFallbackTeams(ctx context.Context, tx pgx.Tx, teamName string, fallbacks []string) error {
+	seen := make(map[string]bool, len(fallbacks))
+	for _, fallback := range fallbacks {
+		if fallback == teamName || seen[fallback] {
+			return models.ErrInvalidFallback
+		}
+		seen[fallback] = true
+
+		if err := s.checkTeamActiveTx(ctx, tx, fallback); err == models.ErrTeamArchived {
+			return models.ErrInvalidFallback
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateParentTx - родитель должен быть другой активной командой и не должен
+// быть подкомандой teamName (иначе получится цикл)
+func (s *TeamService) validateParentTx(ctx context.Context, tx pgx.Tx, teamName string, parentName string) error {
+	if parentName == teamName {
+		return models.ErrInvalidParent
+	}
+
+	err := s.checkTeamActiveTx(ctx, tx, parentName)
+	if err == models.ErrNotFound || err == models.ErrTeamArchived {
+		return models.ErrInvalidParent
+	}
+	if err != nil {
+		return err
+	}
+
+	ancestors, err := s.storage.GetAncestorTeamsTx(ctx, tx, parentName)
+	if err != nil {
+		return err
+	}
+	if contains(ancestors, teamName) {
+		return models.ErrInvalidParent
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package services
+
+/*
+Архивация и удаление команды (ArchiveTeam):
+	1. Участники выходят из команды и вступают в move_members_to (если он задан),
+	   основная команда переходит вместе с ними. В других своих командах они остаются
+	2. OPEN ревью участников в PR этой команды переназначаются, как при деактивации
+	   (при переводе - только с reassign_reviews). Список ревью берется до перевода
+	   участников, а переназначаются они после, чтобы замену не выбрали среди них же
+	3. Команда перестает быть командой по умолчанию репозиториев и запасной у других команд,
+	   ее подкоманды переходят к ее родителю (или становятся корневыми)
+	4. Архивная команда остается (с archived_at) - ее имя по-прежнему видно в истории
+	   и решениях о назначении; удаленная - удаляется вместе со своим CODEOWNERS.
+	   PR и юзеры не удаляются никогда
+
+Без apply все шаги выполняются в транзакции, которая откатывается, - так в плане
+ровно то, что будет сделано. Ревьюеры при применении могут выбраться другие, если
+нагрузка за это время изменилась.
+*/
+
+import (
+	"context"
+	"subscription-budget/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (s *TeamService) ArchiveTeam(ctx context.Context, req models.ArchiveTeamRequest) (*models.TeamArchivePlan, error) {
+	var result *models.TeamArchivePlan
+
+	err := s.executeWithRetryTeam(ctx, func() error {
+		tx, err := s.storage.TeamBeginTx(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+
+		team, err := s.storage.GetTeamInfoTx(ctx, tx, req.TeamName)
+		if err != nil {
+			return err
+		}
+
+		plan := &models.TeamArchivePlan{
+			TeamName:          team.TeamName,
+			Action:            models.TeamActionArchive,
+			Applied:           req.Apply,
+			Members:           []models.MemberMove{},
+			ReassignedReviews: []models.ReviewReassignment{},
+		}
+		if req.Delete {
+			plan.Action = models.TeamActionDelete
+		} else if team.ArchivedAt != nil {
+			return models.ErrTeamArchived
+		}
+
+		if req.MoveMembersTo != "" {
+			target, err := s.storage.GetTeamInfoTx(ctx, tx, req.MoveMembersTo)
+			if err == models.ErrNotFound || (err == nil && (target.TeamName == team.TeamName || target.ArchivedAt != nil)) {
+				return models.ErrInvalidMoveTarget
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		reassign := s.reassigner != nil && (req.MoveMembersTo == "" || req.ReassignReviews)
+		openReviews := make(map[string][]string, len(team.Members))
+		if reassign {
+			for _, member := range team.Members {
+				openReviews[member.UserID], err = s.reassigner.OpenReviewsTx(ctx, tx, member.UserID, team.TeamName)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, member := range team.Members {
+			if err := s.moveMemberTx(ctx, tx, member.UserID, team.TeamName, req.MoveMembersTo); err != nil {
+				return err
+			}
+			plan.Members = append(plan.Members, models.MemberMove{UserID: member.UserID, ToTeam: req.MoveMembersTo})
+		}
+
+		if reassign {
+			for _, member := range team.Members {
+				moved, err := s.reassigner.ReassignReviewsTx(ctx, tx, member.UserID, openReviews[member.UserID])
+				if err != nil {
+					return err
+				}
+				plan.ReassignedReviews = append(plan.ReassignedReviews, moved...)
+			}
+		}
+
+		plan.FallbackOf, err = s.storage.GetFallbackOfTx(ctx, tx, team.TeamName)
+		if err != nil {
+			return err
+		}
+
+		plan.Repositories, err = s.storage.ClearDefaultTeamTx(ctx, tx, team.TeamName)
+		if err != nil {
+			return err
+		}
+
+		plan.Children, err = s.storage.ReparentChildrenTx(ctx, tx, team.TeamName)
+		if err != nil {
+			return err
+		}
+
+		if req.Delete {
+			plan.CodeOwnersRemoved, err = s.storage.DeleteTeamTx(ctx, tx, team.TeamName)
+		} else {
+			err = s.storage.ArchiveTeamTx(ctx, tx, team.TeamName)
+		}
+		if err != nil {
+			return err
+		}
+
+		if req.Apply {
+			if err := tx.Commit(ctx); err != nil {
+				return err
+			}
+		}
+
+		result = plan
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Apply && req.MoveMembersTo != "" && s.notifier != nil {
+		s.notifier.TeamCapacityChanged(req.MoveMembersTo)
+	}
+
+	return result, nil
+}
+
+// moveMemberTx выводит юзера из команды fromTeam и, если toTeam задан, добавляет в нее
+// (основной она становится, если основной была fromTeam). Уже состоящий в toTeam просто выходит
+func (s *TeamService) moveMemberTx(ctx context.Context, tx pgx.Tx, userID string, fromTeam string, toTeam string) error {
+	wasPrimary, err := s.storage.RemoveMembershipTx(ctx, tx, userID, fromTeam)
+	if err != nil || toTeam == "" {
+		return err
+	}
+
+	err = s.storage.AddMembershipTx(ctx, tx, userID, toTeam, wasPrimary)
+	if err == models.ErrAlreadyMember {
+		if wasPrimary {
+			return s.storage.SetPrimaryTeamTx(ctx, tx, userID, toTeam)
+		}
+		return nil
+	}
+	return err
+}
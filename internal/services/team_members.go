@@ -0,0 +1,294 @@
+package services
+
+/*
+Участники команды (юзер может состоять в нескольких командах, одна из них - основная):
+	1. AddMember - юзер вступает в команду, оставаясь в своих командах
+	2. RemoveMember - юзер выходит из команды (PR и ревью на него ссылаются, удалять нельзя)
+	3. MoveMember - перевод юзера из одной команды в другую, основная команда переходит с ним
+	4. SetPrimaryTeam - сменить или снять основную команду юзера
+
+Если у юзера есть OPEN ревью в PR команды, которую он покидает, убрать или перевести его
+можно только с подтверждением: reassign_reviews - эти ревью переназначаются в той же
+транзакции (как при деактивации), confirm - остаются за ним. Ревью в PR других его команд
+не трогаются. Лида команды сначала нужно сменить в настройках.
+В архивную команду добавить или перевести юзера нельзя.
+После вступления в команду запускается добор ревьюеров в ее PR.
+*/
+
+import (
+	"context"
+	"subscription-budget/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (s *TeamService) AddMember(ctx context.Context, req models.AddMemberRequest) (*models.Team, error) {
+	user := req.User
+	user.TeamName = req.TeamName
+	user.Skills = normalizeTags(user.Skills)
+
+	var result *models.Team
+
+	err := s.executeWithRetryTeam(ctx, func() error {
+		tx, err := s.storage.TeamBeginTx(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+
+		if err := s.checkTeamActiveTx(ctx, tx, req.TeamName); err != nil {
+			return err
+		}
+
+		if err := s.storage.AddMemberTx(ctx, tx, user); err != nil {
+			return err
+		}
+
+		if req.Primary {
+			if err := s.storage.SetPrimaryTeamTx(ctx, tx, user.UserID, req.TeamName); err != nil {
+				return err
+			}
+		}
+
+		team, err := s.storage.GetTeamInfoTx(ctx, tx, req.TeamName)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return err
+		}
+
+		result = team
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if s.notifier != nil {
+		s.notifier.TeamCapacityChanged(result.TeamName)
+	}
+
+	return result, nil
+}
+
+func (s *TeamService) RemoveMember(ctx context.Context, req models.RemoveMemberRequest) ([]models.ReviewReassignment, error) {
+	var reassigned []models.ReviewReassignment
+
+	err := s.executeWithRetryTeam(ctx, func() error {
+		tx, err := s.storage.TeamBeginTx(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+
+		memberships, err := s.storage.GetMemberTeamsTx(ctx, tx, req.UserID)
+		if err != nil {
+			return err
+		}
+		if !isMemberOf(memberships, req.TeamName) {
+			return models.ErrNotMember
+		}
+
+		moved, err := s.leaveTeamTx(ctx, tx, req.UserID, req.TeamName, req.ReassignReviews, req.Confirm)
+		if err != nil {
+			return err
+		}
+
+		if _, err := s.storage.RemoveMembershipTx(ctx, tx, req.UserID, req.TeamName); err != nil {
+			return err
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return err
+		}
+
+		reassigned = moved
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return reassigned, nil
+}
+
+func (s *TeamService) MoveMember(ctx context.Context, req models.MoveMemberRequest) (*models.Team, []models.ReviewReassignment, error) {
+	var result *models.Team
+	var reassigned []models.ReviewReassignment
+
+	err := s.executeWithRetryTeam(ctx, func() error {
+		tx, err := s.storage.TeamBeginTx(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+
+		if err := s.checkTeamActiveTx(ctx, tx, req.ToTeam); err != nil {
+			return err
+		}
+
+		memberships, err := s.storage.GetMemberTeamsTx(ctx, tx, req.UserID)
+		if err != nil {
+			return err
+		}
+		fromTeam, err := moveSource(memberships, req)
+		if err != nil {
+			return err
+		}
+
+		moved := []models.ReviewReassignment{}
+		if fromTeam != "" {
+			moved, err = s.leaveTeamTx(ctx, tx, req.UserID, fromTeam, req.ReassignReviews, req.Confirm)
+			if err != nil {
+				return err
+			}
+
+			err = s.moveMemberTx(ctx, tx, req.UserID, fromTeam, req.ToTeam)
+		} else {
+			err = s.storage.AddMembershipTx(ctx, tx, req.UserID, req.ToTeam, false)
+		}
+		if err != nil {
+			return err
+		}
+
+		team, err := s.storage.GetTeamInfoTx(ctx, tx, req.ToTeam)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return err
+		}
+
+		result = team
+		reassigned = moved
+		return nil
+	})
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if s.notifier != nil {
+		s.notifier.TeamCapacityChanged(result.TeamName)
+	}
+
+	return result, reassigned, nil
+}
+
+func (s *TeamService) SetPrimaryTeam(ctx context.Context, req models.SetPrimaryTeamRequest) ([]models.TeamMembership, error) {
+	var result []models.TeamMembership
+
+	err := s.executeWithRetryTeam(ctx, func() error {
+		tx, err := s.storage.TeamBeginTx(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+
+		if _, err := s.storage.GetMemberTeamsTx(ctx, tx, req.UserID); err != nil {
+			return err
+		}
+
+		if err := s.storage.SetPrimaryTeamTx(ctx, tx, req.UserID, req.TeamName); err != nil {
+			return err
+		}
+
+		memberships, err := s.storage.GetMemberTeamsTx(ctx, tx, req.UserID)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return err
+		}
+
+		result = memberships
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func isMemberOf(memberships []models.TeamMembership, teamName string) bool {
+	for _, membership := range memberships {
+		if membership.TeamName == teamName {
+			return true
+		}
+	}
+	return false
+}
+
+// moveSource - из какой команды переводится юзер. Пустая строка - юзер ни в одной команде
+// и просто вступает в ToTeam. Если команд несколько, FromTeam обязателен (ErrAmbiguousTeam)
+func moveSource(memberships []models.TeamMembership, req models.MoveMemberRequest) (string, error) {
+	if isMemberOf(memberships, req.ToTeam) {
+		return "", models.ErrAlreadyMember
+	}
+
+	if req.FromTeam == "" {
+		switch len(memberships) {
+		case 0:
+			return "", nil
+		case 1:
+			return memberships[0].TeamName, nil
+		default:
+			return "", models.ErrAmbiguousTeam
+		}
+	}
+
+	if !isMemberOf(memberships, req.FromTeam) {
+		return "", models.ErrNotMember
+	}
+	return req.FromTeam, nil
+}
+
+// checkTeamActiveTx - ErrNotFound, если команды нет, ErrTeamArchived, если она в архиве
+func (s *TeamService) checkTeamActiveTx(ctx context.Context, tx pgx.Tx, teamName string) error {
+	team, err := s.storage.GetTeamInfoTx(ctx, tx, teamName)
+	if err != nil {
+		return err
+	}
+	if team.ArchivedAt != nil {
+		return models.ErrTeamArchived
+	}
+	return nil
+}
+
+// leaveTeamTx проверяет, что юзер может покинуть команду, и при reassignReviews
+// снимает с него OPEN ревью в PR этой команды. Без reassignReviews и confirm такие ревью - OpenReviewsError
+func (s *TeamService) leaveTeamTx(ctx context.Context, tx pgx.Tx, userID string, teamName string, reassignReviews bool, confirm bool) ([]models.ReviewReassignment, error) {
+	settings, err := s.storage.GetTeamSettingsTx(ctx, tx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	if settings.LeadUserID == userID {
+		return nil, models.ErrTeamLead
+	}
+
+	if s.reassigner == nil {
+		return []models.ReviewReassignment{}, nil
+	}
+
+	if reassignReviews {
+		return s.reassigner.ReassignOpenReviewsTx(ctx, tx, userID, teamName)
+	}
+
+	open, err := s.reassigner.OpenReviewsTx(ctx, tx, userID, teamName)
+	if err != nil {
+		return nil, err
+	}
+	if len(open) > 0 && !confirm {
+		return nil, &models.OpenReviewsError{PullRequestIDs: open}
+	}
+
+	return []models.ReviewReassignment{}, nil
+}
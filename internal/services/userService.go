@@ -2,8 +2,13 @@ package services
 
 /*
 Функции:
-	1. Выставление активности пользоватлеля
+	1. Выставление активности пользоватлеля. При деактивации (если не выключено)
+	   все OPEN ревью юзера в той же транзакции переназначаются на других.
+	   При активации запускается добор ревьюеров в PR всех его команд
 	2. Получение информации о юзере
+	3. Изменение навыков юзера (по ним подбираются ревьюеры под метки PR)
+	4. Отсутствия юзера: добавить, получить список, удалить.
+	   Во время отсутствия юзер не выбирается ревьюером, is_active трогать не нужно
 
 Фича - указываем в GetUserTx nil вместо индекса, он автоматом выполняется через
 пул
@@ -17,11 +22,15 @@ import (
 
 type UserService struct {
 	userStorage storage.UserStorage
+	reassigner  ReviewReassigner
+	notifier    CapacityNotifier
 }
 
-func NewUserService(userStorage storage.UserStorage) *UserService {
+func NewUserService(userStorage storage.UserStorage, reassigner ReviewReassigner, notifier CapacityNotifier) *UserService {
 	return &UserService{
 		userStorage: userStorage,
+		reassigner:  reassigner,
+		notifier:    notifier,
 	}
 }
 
@@ -49,8 +58,9 @@ func (s *UserService) executeWithRetry(ctx context.Context, operation func() err
 	return lastErr
 }
 
-func (s *UserService) SetUserActive(ctx context.Context, userID string, isActive bool) (*models.User, error) {
+func (s *UserService) SetUserActive(ctx context.Context, userID string, isActive bool, reassignReviews bool) (*models.User, []models.ReviewReassignment, error) {
 	var result *models.User
+	var reassigned []models.ReviewReassignment
 
 	err := s.executeWithRetry(ctx, func() error {
 		tx, err := s.userStorage.UserBeginTx(ctx)
@@ -69,19 +79,34 @@ func (s *UserService) SetUserActive(ctx context.Context, userID string, isActive
 			return err
 		}
 
+		moved := []models.ReviewReassignment{}
+		if !isActive && reassignReviews && s.reassigner != nil {
+			moved, err = s.reassigner.ReassignOpenReviewsTx(ctx, tx, userID, "")
+			if err != nil {
+				return err
+			}
+		}
+
 		if err := tx.Commit(ctx); err != nil {
 			return err
 		}
 
 		result = res
+		reassigned = moved
 		return nil
 	})
 
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return result, nil
+	if isActive && s.notifier != nil {
+		for _, teamName := range result.Teams {
+			s.notifier.TeamCapacityChanged(teamName)
+		}
+	}
+
+	return result, reassigned, nil
 }
 
 func (s *UserService) GetUser(ctx context.Context, userID string) (*models.User, error) {
@@ -103,3 +128,108 @@ func (s *UserService) GetUser(ctx context.Context, userID string) (*models.User,
 
 	return result, nil
 }
+
+func (s *UserService) SetUserSkills(ctx context.Context, userID string, skills []string) (*models.User, error) {
+	var result *models.User
+
+	err := s.executeWithRetry(ctx, func() error {
+		tx, err := s.userStorage.UserBeginTx(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+
+		err = s.userStorage.UpdateUserSkillsTx(ctx, tx, userID, normalizeTags(skills))
+		if err != nil {
+			return err
+		}
+
+		res, err := s.userStorage.GetUserTx(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return err
+		}
+
+		result = res
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *UserService) AddAbsence(ctx context.Context, absence models.UserAbsence) (*models.UserAbsence, error) {
+	if !absence.EndsAt.After(absence.StartsAt) {
+		return nil, models.ErrInvalidAbsence
+	}
+
+	var result *models.UserAbsence
+
+	err := s.executeWithRetry(ctx, func() error {
+		tx, err := s.userStorage.UserBeginTx(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+
+		_, err = s.userStorage.GetUserTx(ctx, tx, absence.UserID)
+		if err != nil {
+			return err
+		}
+
+		created, err := s.userStorage.CreateAbsenceTx(ctx, tx, absence)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return err
+		}
+
+		result = created
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *UserService) GetAbsences(ctx context.Context, userID string) ([]models.UserAbsence, error) {
+	var result []models.UserAbsence
+
+	err := s.executeWithRetry(ctx, func() error {
+		_, err := s.userStorage.GetUserTx(ctx, nil, userID)
+		if err != nil {
+			return err
+		}
+
+		absences, err := s.userStorage.GetAbsencesTx(ctx, nil, userID)
+		if err != nil {
+			return err
+		}
+
+		result = absences
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *UserService) DeleteAbsence(ctx context.Context, absenceID int64) error {
+	return s.executeWithRetry(ctx, func() error {
+		return s.userStorage.DeleteAbsenceTx(ctx, nil, absenceID)
+	})
+}
@@ -0,0 +1,87 @@
+package storage
+
+/*
+Основные функции:
+	1. Загрузка (перезапись) CODEOWNERS-файла для команды или репозитория
+	2. Получение файла по области (team/repository) и имени
+
+Файл хранится целиком как текст, разбор происходит в сервисе.
+
+Фича - если Tx - nil, то используем просто pool
+*/
+
+import (
+	"context"
+	"fmt"
+	"subscription-budget/internal/models"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type OwnershipPostgresStorage struct {
+	pool *pgxpool.Pool
+}
+
+func NewOwnershipPostgresStorage(pool *pgxpool.Pool) *OwnershipPostgresStorage {
+	return &OwnershipPostgresStorage{pool: pool}
+}
+
+func (s *OwnershipPostgresStorage) OwnershipBeginTx(ctx context.Context) (pgx.Tx, error) {
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:   pgx.Serializable,
+		AccessMode: pgx.ReadWrite,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	return tx, nil
+}
+
+func (s *OwnershipPostgresStorage) UpsertOwnershipFileTx(ctx context.Context, tx pgx.Tx, file models.OwnershipFile) error {
+	query := `
+		INSERT INTO ownership_files (scope, scope_ref, content, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (scope, scope_ref)
+		DO UPDATE SET content = EXCLUDED.content, updated_at = EXCLUDED.updated_at
+	`
+
+	var err error
+	if tx != nil {
+		_, err = tx.Exec(ctx, query, file.Scope, file.ScopeRef, file.Content, time.Now())
+	} else {
+		_, err = s.pool.Exec(ctx, query, file.Scope, file.ScopeRef, file.Content, time.Now())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save ownership file: %w", err)
+	}
+
+	return nil
+}
+
+func (s *OwnershipPostgresStorage) GetOwnershipFileTx(ctx context.Context, tx pgx.Tx, scope string, scopeRef string) (*models.OwnershipFile, error) {
+	query := `
+		SELECT scope, scope_ref, content, updated_at
+		FROM ownership_files
+		WHERE scope = $1 AND scope_ref = $2
+	`
+
+	var row pgx.Row
+	if tx != nil {
+		row = tx.QueryRow(ctx, query, scope, scopeRef)
+	} else {
+		row = s.pool.QueryRow(ctx, query, scope, scopeRef)
+	}
+
+	var file models.OwnershipFile
+	err := row.Scan(&file.Scope, &file.ScopeRef, &file.Content, &file.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get ownership file: %w", err)
+	}
+
+	return &file, nil
+}
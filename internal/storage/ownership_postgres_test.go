@@ -0,0 +1,105 @@
+package storage
+
+/*
+Тесты через создание контейнера с постгрес
+Проверка:
+	1. Сохранение и чтение CODEOWNERS-файла
+	2. Перезапись файла той же области
+	3. Получение несуществующего файла
+*/
+import (
+	"context"
+	"subscription-budget/internal/models"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+func setupOwnershipTestDB(t *testing.T) *pgxpool.Pool {
+	ctx := context.Background()
+
+	container, err := postgres.Run(ctx,
+		"postgres:15-alpine",
+		postgres.WithDatabase("test_db"),
+		postgres.WithUsername("test_user"),
+		postgres.WithPassword("test_password"),
+		testcontainers.WithWaitStrategy(
+			wait.ForLog("database system is ready to accept connections").
+				WithOccurrence(2),
+		),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, container.Terminate(ctx))
+	})
+
+	connStr, err := container.ConnectionString(ctx)
+	require.NoError(t, err)
+
+	pool, err := pgxpool.New(ctx, connStr)
+	require.NoError(t, err)
+
+	_, err = pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS ownership_files (
+			scope TEXT NOT NULL CHECK (scope IN ('team', 'repository')),
+			scope_ref TEXT NOT NULL,
+			content TEXT NOT NULL,
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			PRIMARY KEY (scope, scope_ref)
+		);
+	`)
+	require.NoError(t, err)
+
+	return pool
+}
+
+func TestOwnershipPostgresStorage_UpsertAndGet(t *testing.T) {
+	pool := setupOwnershipTestDB(t)
+	storage := NewOwnershipPostgresStorage(pool)
+	ctx := context.Background()
+
+	file := models.OwnershipFile{
+		Scope:    models.OwnershipScopeRepository,
+		ScopeRef: "monorepo",
+		Content:  "* @u1\n/db/ @org/dba\n",
+	}
+
+	tx, err := storage.OwnershipBeginTx(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback(ctx)
+
+	err = storage.UpsertOwnershipFileTx(ctx, tx, file)
+	require.NoError(t, err)
+
+	err = tx.Commit(ctx)
+	require.NoError(t, err)
+
+	saved, err := storage.GetOwnershipFileTx(ctx, nil, models.OwnershipScopeRepository, "monorepo")
+	require.NoError(t, err)
+	assert.Equal(t, file.Content, saved.Content)
+	assert.False(t, saved.UpdatedAt.IsZero())
+
+	file.Content = "* @u2\n"
+	err = storage.UpsertOwnershipFileTx(ctx, nil, file)
+	require.NoError(t, err)
+
+	updated, err := storage.GetOwnershipFileTx(ctx, nil, models.OwnershipScopeRepository, "monorepo")
+	require.NoError(t, err)
+	assert.Equal(t, "* @u2\n", updated.Content)
+}
+
+func TestOwnershipPostgresStorage_Get_NotFound(t *testing.T) {
+	pool := setupOwnershipTestDB(t)
+	storage := NewOwnershipPostgresStorage(pool)
+	ctx := context.Background()
+
+	file, err := storage.GetOwnershipFileTx(ctx, nil, models.OwnershipScopeTeam, "nonexistent")
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	assert.Nil(t, file)
+}
@@ -9,6 +9,24 @@ package storage
 	5. По ревьюеру найти PR
 	6. Проверить существование PR
 	7. Создать транзакцию
+	8. Посчитать нагрузку ревьюеров (число OPEN PR на каждом)
+	9. Найти OPEN PR, где пользователь - ревьюер (для переназначения)
+	10. Найти OPEN PR, где ревьюеров меньше target_reviewers (для добора)
+	    Команда PR - команда по умолчанию его репозитория, иначе команда автора
+	11. Сохранить и получить причины выбора ревьюеров (pr_assignment_decisions)
+	12. Сменить статус PR (DRAFT -> OPEN, закрытие, переоткрытие)
+	13. Ревью ревьюеров (pull_request_reviews): отправить решение, запросить повторно,
+	    получить по PR. Строки PENDING заводятся/удаляются автоматически при
+	    создании PR и любом изменении assigned_reviewers
+	14. История PR (pull_request_history): добавить запись, получить по PR
+	15. Ветки комментариев (pr_comment_threads, pr_comments): открыть ветку,
+	    ответить, resolve/unresolve, получить ветки PR, посчитать нерешенные
+	16. Изменение метаданных PR (название, описание, метки) с проверкой версии
+	17. Зависимости PR (pull_request_dependencies): задать родителей PR,
+	    получить весь стек - все PR, связанные с данным через зависимости
+
+"Открытые" PR - в статусах OPEN и REOPENED (models.OpenStatuses): только на них
+считается нагрузка, переназначаются и добираются ревьюеры.
 
 
 
@@ -40,19 +58,33 @@ func (s *PullRequestPostgresStorage) CreatePRTx(ctx context.Context, tx pgx.Tx,
 		INSERT INTO pull_requests (
 			pull_request_id, 
 			pull_request_name, 
+			description,
 			author_id, 
 			status, 
 			assigned_reviewers,
+			target_reviewers,
+			cross_team_reviewers,
+			labels,
+			repository,
+			number,
+			team_name,
 			created_at
-		) VALUES ($1, $2, $3, $4, $5, $6)
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NULLIF($10, ''), NULLIF($11, 0), NULLIF($12, ''), $13)
 	`
 
 	_, err := tx.Exec(ctx, query,
 		pr.PullRequestID,
 		pr.PullRequestName,
+		pr.Description,
 		pr.AuthorID,
 		pr.Status,
-		pr.AssignedReviewers,
+		nonNilSlice(pr.AssignedReviewers),
+		pr.TargetReviewers,
+		nonNilSlice(pr.CrossTeamReviewers),
+		nonNilSlice(pr.Labels),
+		pr.Repository,
+		pr.Number,
+		pr.TeamName,
 		time.Now(),
 	)
 
@@ -60,43 +92,99 @@ func (s *PullRequestPostgresStorage) CreatePRTx(ctx context.Context, tx pgx.Tx,
 		return fmt.Errorf("failed to create PR: %w", err)
 	}
 
-	return nil
+	return s.syncReviewRequestsTx(ctx, tx, pr.PullRequestID, pr.AssignedReviewers)
 }
 
-func (s *PullRequestPostgresStorage) GetPRByIDTx(ctx context.Context, tx pgx.Tx, prID string) (*models.PullRequest, error) {
-	query := `
-		SELECT 
+// prColumns - колонки, которые читает scanPR, в том же порядке
+const prColumns = `
 			pull_request_id,
 			pull_request_name,
+			description,
 			author_id,
 			status,
 			assigned_reviewers,
+			target_reviewers,
+			cross_team_reviewers,
+			labels,
+			COALESCE(repository, ''),
+			COALESCE(number, 0),
+			COALESCE(team_name, ''),
+			version,
+			ARRAY(
+				SELECT d.parent_id FROM pull_request_dependencies d
+				WHERE d.pull_request_id = pull_requests.pull_request_id
+				ORDER BY d.parent_id
+			),
 			created_at,
-			merged_at
-		FROM pull_requests 
-		WHERE pull_request_id = $1
-	`
+			merged_at,
+			closed_at`
 
+// prTeamSQL - команда PR в SQL, как в prTeamTx сервиса: выбранная при создании,
+// иначе команда по умолчанию репозитория, иначе основная (или первая) команда автора
+func prTeamSQL(alias string) string {
+	return `COALESCE(
+				` + alias + `.team_name,
+				(SELECT default_team FROM repositories WHERE name = ` + alias + `.repository),
+				(SELECT m.team_name FROM team_memberships m
+					WHERE m.user_id = ` + alias + `.author_id
+					ORDER BY m.is_primary DESC, m.joined_at, m.team_name
+					LIMIT 1)
+			)`
+}
+
+func scanPR(row pgx.Row) (*models.PullRequest, error) {
 	var pr models.PullRequest
 	var mergedAt *time.Time
-
-	var row pgx.Row
-	if tx != nil {
-		row = tx.QueryRow(ctx, query, prID)
-	} else {
-		row = s.pool.QueryRow(ctx, query, prID)
-	}
+	var closedAt *time.Time
 
 	err := row.Scan(
 		&pr.PullRequestID,
 		&pr.PullRequestName,
+		&pr.Description,
 		&pr.AuthorID,
 		&pr.Status,
 		&pr.AssignedReviewers,
+		&pr.TargetReviewers,
+		&pr.CrossTeamReviewers,
+		&pr.Labels,
+		&pr.Repository,
+		&pr.Number,
+		&pr.TeamName,
+		&pr.Version,
+		&pr.DependsOn,
 		&pr.CreatedAt,
 		&mergedAt,
+		&closedAt,
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	if mergedAt != nil {
+		pr.MergedAt = mergedAt
+	}
+	if closedAt != nil {
+		pr.ClosedAt = closedAt
+	}
+
+	return &pr, nil
+}
+
+func (s *PullRequestPostgresStorage) GetPRByIDTx(ctx context.Context, tx pgx.Tx, prID string) (*models.PullRequest, error) {
+	query := `
+		SELECT ` + prColumns + `
+		FROM pull_requests 
+		WHERE pull_request_id = $1
+	`
+
+	var row pgx.Row
+	if tx != nil {
+		row = tx.QueryRow(ctx, query, prID)
+	} else {
+		row = s.pool.QueryRow(ctx, query, prID)
+	}
 
+	pr, err := scanPR(row)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, models.ErrNotFound
@@ -104,27 +192,230 @@ func (s *PullRequestPostgresStorage) GetPRByIDTx(ctx context.Context, tx pgx.Tx,
 		return nil, fmt.Errorf("failed to get PR: %w", err)
 	}
 
-	if mergedAt != nil {
-		pr.MergedAt = mergedAt
+	return pr, nil
+}
+
+func (s *PullRequestPostgresStorage) GetOpenPRsByReviewerTx(ctx context.Context, tx pgx.Tx, userID string) ([]models.PullRequest, error) {
+	query := `
+		SELECT ` + prColumns + `
+		FROM pull_requests 
+		WHERE $1 = ANY(assigned_reviewers) AND status = ANY($2)
+		ORDER BY created_at
+	`
+
+	var rows pgx.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.Query(ctx, query, userID, models.OpenStatuses)
+	} else {
+		rows, err = s.pool.Query(ctx, query, userID, models.OpenStatuses)
 	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query open PRs by reviewer: %w", err)
+	}
+	defer rows.Close()
 
-	return &pr, nil
+	var prs []models.PullRequest
+	for rows.Next() {
+		pr, err := scanPR(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan PR: %w", err)
+		}
+		prs = append(prs, *pr)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating PRs: %w", err)
+	}
+
+	return prs, nil
+}
+
+// GetUnderstaffedPRsTx ищет OPEN PR с недобором ревьюеров.
+// Если teamName пустой - по всем командам, иначе только PR этой команды (см. prTeamSQL)
+func (s *PullRequestPostgresStorage) GetUnderstaffedPRsTx(ctx context.Context, tx pgx.Tx, teamName string) ([]models.PullRequest, error) {
+	query := `
+		SELECT ` + prColumns + `
+		FROM pull_requests 
+		WHERE status = ANY($1)
+			AND cardinality(assigned_reviewers) < target_reviewers
+			AND ($2 = '' OR ` + prTeamSQL("pull_requests") + ` = $2)
+		ORDER BY created_at
+	`
+
+	var rows pgx.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.Query(ctx, query, models.OpenStatuses, teamName)
+	} else {
+		rows, err = s.pool.Query(ctx, query, models.OpenStatuses, teamName)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query understaffed PRs: %w", err)
+	}
+	defer rows.Close()
+
+	var prs []models.PullRequest
+	for rows.Next() {
+		pr, err := scanPR(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan PR: %w", err)
+		}
+		prs = append(prs, *pr)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating PRs: %w", err)
+	}
+
+	return prs, nil
+}
+
+// GetStaleCandidatesTx - открытые PR команд с включенной проверкой устаревания, со временем
+// последней активности: создание, события истории (кроме системных), отправленные ревью
+// и комментарии. Команда PR - см. prTeamSQL.
+// Пустой teamName - по всем командам
+func (s *PullRequestPostgresStorage) GetStaleCandidatesTx(ctx context.Context, tx pgx.Tx, teamName string) ([]models.StalePR, error) {
+	return s.queryStaleCandidatesTx(ctx, tx, teamName, "")
+}
+
+// GetStaleCandidateTx - то же, что GetStaleCandidatesTx, для одного PR.
+// ErrNotFound, если PR уже не открыт или проверка у его команды выключена
+func (s *PullRequestPostgresStorage) GetStaleCandidateTx(ctx context.Context, tx pgx.Tx, prID string) (*models.StalePR, error) {
+	candidates, err := s.queryStaleCandidatesTx(ctx, tx, "", prID)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return nil, models.ErrNotFound
+	}
+	return &candidates[0], nil
+}
+
+// queryStaleCandidatesTx - кандидаты на устаревание; пустые teamName и prID - без фильтра
+func (s *PullRequestPostgresStorage) queryStaleCandidatesTx(ctx context.Context, tx pgx.Tx, teamName string, prID string) ([]models.StalePR, error) {
+	query := `
+		WITH activity AS (
+			SELECT
+				p.pull_request_id,
+				p.pull_request_name,
+				p.author_id,
+				p.status,
+				COALESCE(p.repository, '') AS repository,
+				p.stale_at,
+				` + prTeamSQL("p") + ` AS team_name,
+				GREATEST(
+					p.created_at,
+					(SELECT MAX(h.created_at) FROM pull_request_history h
+						WHERE h.pull_request_id = p.pull_request_id AND NOT (h.event = ANY($2))),
+					(SELECT MAX(r.submitted_at) FROM pull_request_reviews r
+						WHERE r.pull_request_id = p.pull_request_id),
+					(SELECT MAX(c.created_at) FROM pr_comments c
+						JOIN pr_comment_threads t ON t.thread_id = c.thread_id
+						WHERE t.pull_request_id = p.pull_request_id)
+				) AS last_activity_at
+			FROM pull_requests p
+			WHERE p.status = ANY($1)
+				AND ($4 = '' OR p.pull_request_id = $4)
+		)
+		SELECT
+			a.pull_request_id,
+			a.pull_request_name,
+			a.author_id,
+			a.status,
+			a.repository,
+			a.team_name,
+			a.last_activity_at,
+			a.stale_at,
+			t.stale_after_days,
+			t.stale_close_after_days
+		FROM activity a
+		JOIN teams t ON t.name = a.team_name
+		WHERE t.stale_enabled
+			AND ($3 = '' OR a.team_name = $3)
+		ORDER BY a.last_activity_at
+	`
+
+	var rows pgx.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.Query(ctx, query, models.OpenStatuses, models.SystemPREvents, teamName, prID)
+	} else {
+		rows, err = s.pool.Query(ctx, query, models.OpenStatuses, models.SystemPREvents, teamName, prID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stale candidates: %w", err)
+	}
+	defer rows.Close()
+
+	prs := []models.StalePR{}
+	for rows.Next() {
+		var pr models.StalePR
+		err := rows.Scan(
+			&pr.PullRequestID,
+			&pr.PullRequestName,
+			&pr.AuthorID,
+			&pr.Status,
+			&pr.Repository,
+			&pr.TeamName,
+			&pr.LastActivityAt,
+			&pr.StaleAt,
+			&pr.StaleAfterDays,
+			&pr.StaleCloseAfterDays,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan stale candidate: %w", err)
+		}
+		prs = append(prs, pr)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating stale candidates: %w", err)
+	}
+
+	return prs, nil
+}
+
+// SetPRStaleTx помечает PR устаревшим с момента staleAt; nil снимает отметку
+func (s *PullRequestPostgresStorage) SetPRStaleTx(ctx context.Context, tx pgx.Tx, prID string, staleAt *time.Time) error {
+	query := `
+		UPDATE pull_requests
+		SET stale_at = $1
+		WHERE pull_request_id = $2
+	`
+
+	var result pgconn.CommandTag
+	var err error
+	if tx != nil {
+		result, err = tx.Exec(ctx, query, staleAt, prID)
+	} else {
+		result, err = s.pool.Exec(ctx, query, staleAt, prID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set PR stale: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+
+	return nil
 }
 
 func (s *PullRequestPostgresStorage) MergePRTx(ctx context.Context, tx pgx.Tx, prID string) error {
 	query := `
 		UPDATE pull_requests 
 		SET status = $1, merged_at = $2
-		WHERE pull_request_id = $3 AND status != $1
+		WHERE pull_request_id = $3 AND status = ANY($4)
 	`
 
 	var result pgconn.CommandTag
 	var err error
 
 	if tx != nil {
-		result, err = tx.Exec(ctx, query, "MERGED", time.Now(), prID)
+		result, err = tx.Exec(ctx, query, models.PRStatusMerged, time.Now(), prID, models.OpenStatuses)
 	} else {
-		result, err = s.pool.Exec(ctx, query, "MERGED", time.Now(), prID)
+		result, err = s.pool.Exec(ctx, query, models.PRStatusMerged, time.Now(), prID, models.OpenStatuses)
 	}
 
 	if err != nil {
@@ -144,10 +435,19 @@ func (s *PullRequestPostgresStorage) MergePRTx(ctx context.Context, tx pgx.Tx, p
 	return nil
 }
 
-func (s *PullRequestPostgresStorage) UpdatePRReviewersTx(ctx context.Context, tx pgx.Tx, prID string, reviewers []string) error {
+// UpdatePRStatusTx переводит PR из статуса from в to. Допустимость перехода
+// проверяет сервис; если PR уже не в статусе from - ErrInvalidTransition.
+// При закрытии ставится closed_at, при переоткрытии сбрасываются closed_at и stale_at
+func (s *PullRequestPostgresStorage) UpdatePRStatusTx(ctx context.Context, tx pgx.Tx, prID string, from string, to string) error {
 	query := `
 		UPDATE pull_requests 
-		SET assigned_reviewers = $1
+		SET status = $1::text,
+			closed_at = CASE
+				WHEN $1::text = 'CLOSED' THEN NOW()
+				WHEN $1::text = 'REOPENED' THEN NULL
+				ELSE closed_at
+			END,
+			stale_at = CASE WHEN $1::text = 'REOPENED' THEN NULL ELSE stale_at END
 		WHERE pull_request_id = $2 AND status = $3
 	`
 
@@ -155,91 +455,944 @@ func (s *PullRequestPostgresStorage) UpdatePRReviewersTx(ctx context.Context, tx
 	var err error
 
 	if tx != nil {
-		result, err = tx.Exec(ctx, query, reviewers, prID, "OPEN")
+		result, err = tx.Exec(ctx, query, to, prID, from)
 	} else {
-		result, err = s.pool.Exec(ctx, query, reviewers, prID, "OPEN")
+		result, err = s.pool.Exec(ctx, query, to, prID, from)
 	}
 
 	if err != nil {
-		return fmt.Errorf("failed to update PR reviewers: %w", err)
+		return fmt.Errorf("failed to update PR status: %w", err)
 	}
 
 	if result.RowsAffected() == 0 {
-		return models.ErrPRMerged
+		exists, err := s.checkPRExistsTx(ctx, tx, prID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return models.ErrNotFound
+		}
+		return models.ErrInvalidTransition
 	}
 
 	return nil
 }
 
-func (s *PullRequestPostgresStorage) GetPRsByReviewerTx(ctx context.Context, tx pgx.Tx, userID string) ([]models.PullRequestShort, error) {
+// UpdatePRMetadataTx сохраняет название, описание и метки, если версия PR все еще version,
+// и увеличивает ее. ErrVersionConflict, если PR успели изменить (или его нет)
+func (s *PullRequestPostgresStorage) UpdatePRMetadataTx(ctx context.Context, tx pgx.Tx, pr models.PullRequest, version int) error {
 	query := `
-		SELECT 
-			pull_request_id,
-			pull_request_name,
-			author_id,
-			status
-		FROM pull_requests 
-		WHERE $1 = ANY(assigned_reviewers)
-		ORDER BY created_at DESC
+		UPDATE pull_requests
+		SET pull_request_name = $1,
+			description = $2,
+			labels = $3,
+			version = version + 1
+		WHERE pull_request_id = $4 AND version = $5
 	`
+	args := []any{
+		pr.PullRequestName,
+		pr.Description,
+		nonNilSlice(pr.Labels),
+		pr.PullRequestID,
+		version,
+	}
 
-	var rows pgx.Rows
+	var result pgconn.CommandTag
+	var err error
+	if tx != nil {
+		result, err = tx.Exec(ctx, query, args...)
+	} else {
+		result, err = s.pool.Exec(ctx, query, args...)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update PR metadata: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return models.ErrVersionConflict
+	}
+
+	return nil
+}
+
+func (s *PullRequestPostgresStorage) UpdatePRReviewersTx(ctx context.Context, tx pgx.Tx, prID string, reviewers []string, crossTeam []string) error {
+	query := `
+		UPDATE pull_requests 
+		SET assigned_reviewers = $1, cross_team_reviewers = $2
+		WHERE pull_request_id = $3 AND status = ANY($4)
+	`
+
+	var result pgconn.CommandTag
 	var err error
 
 	if tx != nil {
-		rows, err = tx.Query(ctx, query, userID)
+		result, err = tx.Exec(ctx, query, nonNilSlice(reviewers), nonNilSlice(crossTeam), prID, models.OpenStatuses)
 	} else {
-		rows, err = s.pool.Query(ctx, query, userID)
+		result, err = s.pool.Exec(ctx, query, nonNilSlice(reviewers), nonNilSlice(crossTeam), prID, models.OpenStatuses)
 	}
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to query PRs by reviewer: %w", err)
+		return fmt.Errorf("failed to update PR reviewers: %w", err)
 	}
-	defer rows.Close()
 
-	var prs []models.PullRequestShort
-	for rows.Next() {
-		var pr models.PullRequestShort
-		err := rows.Scan(
-			&pr.PullRequestID,
-			&pr.PullRequestName,
-			&pr.AuthorID,
-			&pr.Status,
-		)
+	if result.RowsAffected() == 0 {
+		// Ни одной строки: PR нет или он не открыт - уточняем по его статусу
+		pr, err := s.GetPRByIDTx(ctx, tx, prID)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan PR: %w", err)
+			return err
 		}
-		prs = append(prs, pr)
+		if pr.Status == models.PRStatusMerged {
+			return models.ErrPRMerged
+		}
+		return models.ErrPRNotOpen
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating PRs: %w", err)
+	return s.syncReviewRequestsTx(ctx, tx, prID, reviewers)
+}
+
+// syncReviewRequestsTx заводит PENDING-ревью новым ревьюерам и удаляет ревью снятых.
+// Решения оставшихся ревьюеров не трогаются
+func (s *PullRequestPostgresStorage) syncReviewRequestsTx(ctx context.Context, tx pgx.Tx, prID string, reviewers []string) error {
+	deleteQuery := `
+		DELETE FROM pull_request_reviews
+		WHERE pull_request_id = $1 AND NOT (reviewer_id = ANY($2))
+	`
+	insertQuery := `
+		INSERT INTO pull_request_reviews (pull_request_id, reviewer_id)
+		SELECT $1, reviewer FROM unnest($2::text[]) AS reviewer
+		ON CONFLICT (pull_request_id, reviewer_id) DO NOTHING
+	`
+
+	var err error
+	if tx != nil {
+		_, err = tx.Exec(ctx, deleteQuery, prID, nonNilSlice(reviewers))
+	} else {
+		_, err = s.pool.Exec(ctx, deleteQuery, prID, nonNilSlice(reviewers))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to remove review requests: %w", err)
 	}
 
-	return prs, nil
-}
+	if tx != nil {
+		_, err = tx.Exec(ctx, insertQuery, prID, nonNilSlice(reviewers))
+	} else {
+		_, err = s.pool.Exec(ctx, insertQuery, prID, nonNilSlice(reviewers))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to add review requests: %w", err)
+	}
 
-func (s *PullRequestPostgresStorage) PRBeginTx(ctx context.Context) (pgx.Tx, error) {
-	return s.pool.BeginTx(ctx, pgx.TxOptions{
-		IsoLevel:   pgx.Serializable,
-		AccessMode: pgx.ReadWrite,
-	})
+	return nil
 }
 
-func (s *PullRequestPostgresStorage) checkPRExistsTx(ctx context.Context, tx pgx.Tx, prID string) (bool, error) {
-	var exists bool
+// SubmitReviewTx сохраняет решение ревьюера. ErrNotAssigned, если ревью у него не запрашивалось
+func (s *PullRequestPostgresStorage) SubmitReviewTx(ctx context.Context, tx pgx.Tx, review models.SubmitReviewRequest) error {
+	query := `
+		UPDATE pull_request_reviews
+		SET state = $1, body = $2, submitted_at = NOW()
+		WHERE pull_request_id = $3 AND reviewer_id = $4
+	`
 
-	var row pgx.Row
+	var result pgconn.CommandTag
+	var err error
 	if tx != nil {
-		row = tx.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM pull_requests WHERE pull_request_id = $1)", prID)
+		result, err = tx.Exec(ctx, query, review.State, review.Body, review.PullRequestID, review.ReviewerID)
 	} else {
-		row = s.pool.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM pull_requests WHERE pull_request_id = $1)", prID)
+		result, err = s.pool.Exec(ctx, query, review.State, review.Body, review.PullRequestID, review.ReviewerID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to submit review: %w", err)
 	}
 
-	err := row.Scan(&exists)
+	if result.RowsAffected() == 0 {
+		return models.ErrNotAssigned
+	}
+
+	return nil
+}
+
+// ResetReviewTx заново запрашивает ревью: решение сбрасывается в PENDING
+func (s *PullRequestPostgresStorage) ResetReviewTx(ctx context.Context, tx pgx.Tx, prID string, reviewerID string) error {
+	query := `
+		UPDATE pull_request_reviews
+		SET state = $1, body = '', requested_at = NOW(), submitted_at = NULL, overdue_at = NULL
+		WHERE pull_request_id = $2 AND reviewer_id = $3
+	`
+
+	var result pgconn.CommandTag
+	var err error
+	if tx != nil {
+		result, err = tx.Exec(ctx, query, models.ReviewStatePending, prID, reviewerID)
+	} else {
+		result, err = s.pool.Exec(ctx, query, models.ReviewStatePending, prID, reviewerID)
+	}
 	if err != nil {
-		return false, fmt.Errorf("failed to check PR existence: %w", err)
+		return fmt.Errorf("failed to reset review: %w", err)
 	}
 
-	return exists, nil
+	if result.RowsAffected() == 0 {
+		return models.ErrNotAssigned
+	}
+
+	return nil
+}
+
+func (s *PullRequestPostgresStorage) GetPRReviewsTx(ctx context.Context, tx pgx.Tx, prID string) ([]models.PullRequestReview, error) {
+	query := `
+		SELECT pull_request_id, reviewer_id, state, body, requested_at, submitted_at, overdue_at
+		FROM pull_request_reviews
+		WHERE pull_request_id = $1
+		ORDER BY reviewer_id
+	`
+
+	var rows pgx.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.Query(ctx, query, prID)
+	} else {
+		rows, err = s.pool.Query(ctx, query, prID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reviews: %w", err)
+	}
+	defer rows.Close()
+
+	reviews := []models.PullRequestReview{}
+	for rows.Next() {
+		var review models.PullRequestReview
+		err := rows.Scan(
+			&review.PullRequestID,
+			&review.ReviewerID,
+			&review.State,
+			&review.Body,
+			&review.RequestedAt,
+			&review.SubmittedAt,
+			&review.OverdueAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan review: %w", err)
+		}
+		reviews = append(reviews, review)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reviews: %w", err)
+	}
+
+	return reviews, nil
+}
+
+// GetPendingReviewsTx - еще не просроченные PENDING-ревью в открытых PR, старые первыми.
+// Только в PR команд с SLA (review_sla_hours > 0, команда PR - см. prTeamSQL)
+func (s *PullRequestPostgresStorage) GetPendingReviewsTx(ctx context.Context, tx pgx.Tx) ([]models.PullRequestReview, error) {
+	query := `
+		SELECT r.pull_request_id, r.reviewer_id, r.state, r.body, r.requested_at, r.submitted_at, r.overdue_at
+		FROM pull_request_reviews r
+		JOIN pull_requests p ON p.pull_request_id = r.pull_request_id
+		JOIN teams t ON t.name = ` + prTeamSQL("p") + `
+		WHERE r.state = $1
+			AND r.overdue_at IS NULL
+			AND p.status = ANY($2)
+			AND t.review_sla_hours > 0
+		ORDER BY r.requested_at
+	`
+
+	var rows pgx.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.Query(ctx, query, models.ReviewStatePending, models.OpenStatuses)
+	} else {
+		rows, err = s.pool.Query(ctx, query, models.ReviewStatePending, models.OpenStatuses)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pending reviews: %w", err)
+	}
+	defer rows.Close()
+
+	reviews := []models.PullRequestReview{}
+	for rows.Next() {
+		var review models.PullRequestReview
+		err := rows.Scan(
+			&review.PullRequestID,
+			&review.ReviewerID,
+			&review.State,
+			&review.Body,
+			&review.RequestedAt,
+			&review.SubmittedAt,
+			&review.OverdueAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan review: %w", err)
+		}
+		reviews = append(reviews, review)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reviews: %w", err)
+	}
+
+	return reviews, nil
+}
+
+// MarkReviewOverdueTx помечает PENDING-ревью просроченным.
+// ErrNotAssigned, если ревью уже отправлено, снято или помечено раньше
+func (s *PullRequestPostgresStorage) MarkReviewOverdueTx(ctx context.Context, tx pgx.Tx, prID string, reviewerID string) error {
+	query := `
+		UPDATE pull_request_reviews
+		SET overdue_at = NOW()
+		WHERE pull_request_id = $1 AND reviewer_id = $2
+			AND state = $3 AND overdue_at IS NULL
+	`
+
+	var result pgconn.CommandTag
+	var err error
+	if tx != nil {
+		result, err = tx.Exec(ctx, query, prID, reviewerID, models.ReviewStatePending)
+	} else {
+		result, err = s.pool.Exec(ctx, query, prID, reviewerID, models.ReviewStatePending)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to mark review overdue: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return models.ErrNotAssigned
+	}
+
+	return nil
+}
+
+// CountOverdueReviewsTx - сколько просроченных ревью еще ждут ответа в открытых PR
+func (s *PullRequestPostgresStorage) CountOverdueReviewsTx(ctx context.Context, tx pgx.Tx) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM pull_request_reviews r
+		JOIN pull_requests p ON p.pull_request_id = r.pull_request_id
+		WHERE r.state = $1
+			AND r.overdue_at IS NOT NULL
+			AND p.status = ANY($2)
+	`
+
+	var row pgx.Row
+	if tx != nil {
+		row = tx.QueryRow(ctx, query, models.ReviewStatePending, models.OpenStatuses)
+	} else {
+		row = s.pool.QueryRow(ctx, query, models.ReviewStatePending, models.OpenStatuses)
+	}
+
+	var count int
+	if err := row.Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count overdue reviews: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetPRsByReviewerTx - PR, где пользователь ревьюер. Пустой repository - во всех репозиториях
+func (s *PullRequestPostgresStorage) GetPRsByReviewerTx(ctx context.Context, tx pgx.Tx, userID string, repository string) ([]models.PullRequestShort, error) {
+	query := `
+		SELECT 
+			p.pull_request_id,
+			p.pull_request_name,
+			p.author_id,
+			p.status,
+			COALESCE(p.repository, ''),
+			COALESCE(r.state, $2),
+			COALESCE(r.state, $2) = $2 AND p.status = ANY($4),
+			r.overdue_at IS NOT NULL
+		FROM pull_requests p
+		LEFT JOIN pull_request_reviews r
+			ON r.pull_request_id = p.pull_request_id AND r.reviewer_id = $1
+		WHERE $1 = ANY(p.assigned_reviewers)
+			AND ($3 = '' OR p.repository = $3)
+		ORDER BY p.created_at DESC
+	`
+
+	var rows pgx.Rows
+	var err error
+
+	if tx != nil {
+		rows, err = tx.Query(ctx, query, userID, models.ReviewStatePending, repository, models.OpenStatuses)
+	} else {
+		rows, err = s.pool.Query(ctx, query, userID, models.ReviewStatePending, repository, models.OpenStatuses)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query PRs by reviewer: %w", err)
+	}
+	defer rows.Close()
+
+	var prs []models.PullRequestShort
+	for rows.Next() {
+		var pr models.PullRequestShort
+		var overdue bool
+		err := rows.Scan(
+			&pr.PullRequestID,
+			&pr.PullRequestName,
+			&pr.AuthorID,
+			&pr.Status,
+			&pr.Repository,
+			&pr.ReviewState,
+			&pr.ReviewOwed,
+			&overdue,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan PR: %w", err)
+		}
+		// ReviewOwed считается в запросе: ревью не отправлено, а статус PR - из models.OpenStatuses
+		pr.ReviewOverdue = pr.ReviewOwed && overdue
+		prs = append(prs, pr)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating PRs: %w", err)
+	}
+
+	return prs, nil
+}
+
+func (s *PullRequestPostgresStorage) GetOpenReviewLoadTx(ctx context.Context, tx pgx.Tx, userIDs []string) (map[string]int, error) {
+	query := `
+		SELECT reviewer, COUNT(*)
+		FROM pull_requests, unnest(assigned_reviewers) AS reviewer
+		WHERE status = ANY($1) AND reviewer = ANY($2)
+		GROUP BY reviewer
+	`
+
+	var rows pgx.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.Query(ctx, query, models.OpenStatuses, userIDs)
+	} else {
+		rows, err = s.pool.Query(ctx, query, models.OpenStatuses, userIDs)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query review load: %w", err)
+	}
+	defer rows.Close()
+
+	load := make(map[string]int, len(userIDs))
+	for _, userID := range userIDs {
+		load[userID] = 0
+	}
+	for rows.Next() {
+		var userID string
+		var count int
+		if err := rows.Scan(&userID, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan review load: %w", err)
+		}
+		load[userID] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating review load: %w", err)
+	}
+
+	return load, nil
+}
+
+func (s *PullRequestPostgresStorage) CreateAssignmentDecisionsTx(ctx context.Context, tx pgx.Tx, decisions []models.AssignmentDecision) error {
+	query := `
+		INSERT INTO pr_assignment_decisions (
+			pull_request_id,
+			reviewer_id,
+			action,
+			rule,
+			team_name,
+			strategy,
+			replaced_user_id,
+			considered,
+			excluded
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	for _, d := range decisions {
+		considered := d.Considered
+		if considered == nil {
+			considered = []models.ConsideredCandidate{}
+		}
+		excluded := d.Excluded
+		if excluded == nil {
+			excluded = []models.CandidateExclusion{}
+		}
+
+		args := []any{
+			d.PullRequestID,
+			d.ReviewerID,
+			d.Action,
+			d.Rule,
+			d.TeamName,
+			d.Strategy,
+			d.ReplacedUserID,
+			considered,
+			excluded,
+		}
+
+		var err error
+		if tx != nil {
+			_, err = tx.Exec(ctx, query, args...)
+		} else {
+			_, err = s.pool.Exec(ctx, query, args...)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to save assignment decision for %s: %w", d.ReviewerID, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *PullRequestPostgresStorage) GetAssignmentDecisionsTx(ctx context.Context, tx pgx.Tx, prID string) ([]models.AssignmentDecision, error) {
+	query := `
+		SELECT
+			decision_id,
+			pull_request_id,
+			reviewer_id,
+			action,
+			rule,
+			team_name,
+			strategy,
+			replaced_user_id,
+			considered,
+			excluded,
+			created_at
+		FROM pr_assignment_decisions
+		WHERE pull_request_id = $1
+		ORDER BY decision_id
+	`
+
+	var rows pgx.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.Query(ctx, query, prID)
+	} else {
+		rows, err = s.pool.Query(ctx, query, prID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query assignment decisions: %w", err)
+	}
+	defer rows.Close()
+
+	decisions := []models.AssignmentDecision{}
+	for rows.Next() {
+		var d models.AssignmentDecision
+		err := rows.Scan(
+			&d.DecisionID,
+			&d.PullRequestID,
+			&d.ReviewerID,
+			&d.Action,
+			&d.Rule,
+			&d.TeamName,
+			&d.Strategy,
+			&d.ReplacedUserID,
+			&d.Considered,
+			&d.Excluded,
+			&d.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan assignment decision: %w", err)
+		}
+		decisions = append(decisions, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating assignment decisions: %w", err)
+	}
+
+	return decisions, nil
+}
+
+func (s *PullRequestPostgresStorage) AddPRHistoryTx(ctx context.Context, tx pgx.Tx, entry models.PRHistoryEntry) error {
+	query := `
+		INSERT INTO pull_request_history (pull_request_id, event, from_status, to_status, actor, details)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	details := entry.Details
+	if details == nil {
+		details = map[string]any{}
+	}
+
+	args := []any{
+		entry.PullRequestID,
+		entry.Event,
+		entry.FromStatus,
+		entry.ToStatus,
+		entry.Actor,
+		details,
+	}
+
+	var err error
+	if tx != nil {
+		_, err = tx.Exec(ctx, query, args...)
+	} else {
+		_, err = s.pool.Exec(ctx, query, args...)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to add PR history entry: %w", err)
+	}
+
+	return nil
+}
+
+func (s *PullRequestPostgresStorage) GetPRHistoryTx(ctx context.Context, tx pgx.Tx, prID string) ([]models.PRHistoryEntry, error) {
+	query := `
+		SELECT history_id, pull_request_id, event, from_status, to_status, actor, details, created_at
+		FROM pull_request_history
+		WHERE pull_request_id = $1
+		ORDER BY history_id
+	`
+
+	var rows pgx.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.Query(ctx, query, prID)
+	} else {
+		rows, err = s.pool.Query(ctx, query, prID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query PR history: %w", err)
+	}
+	defer rows.Close()
+
+	history := []models.PRHistoryEntry{}
+	for rows.Next() {
+		var entry models.PRHistoryEntry
+		err := rows.Scan(
+			&entry.HistoryID,
+			&entry.PullRequestID,
+			&entry.Event,
+			&entry.FromStatus,
+			&entry.ToStatus,
+			&entry.Actor,
+			&entry.Details,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan PR history entry: %w", err)
+		}
+		history = append(history, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating PR history: %w", err)
+	}
+
+	return history, nil
+}
+
+// CreateCommentThreadTx создает ветку без комментариев, первый добавляется через AddCommentTx
+func (s *PullRequestPostgresStorage) CreateCommentThreadTx(ctx context.Context, tx pgx.Tx, thread models.CommentThread) (int64, error) {
+	query := `
+		INSERT INTO pr_comment_threads (pull_request_id, author_id, file_path, line)
+		VALUES ($1, $2, $3, $4)
+		RETURNING thread_id
+	`
+
+	var row pgx.Row
+	if tx != nil {
+		row = tx.QueryRow(ctx, query, thread.PullRequestID, thread.AuthorID, thread.FilePath, thread.Line)
+	} else {
+		row = s.pool.QueryRow(ctx, query, thread.PullRequestID, thread.AuthorID, thread.FilePath, thread.Line)
+	}
+
+	var threadID int64
+	if err := row.Scan(&threadID); err != nil {
+		return 0, fmt.Errorf("failed to create comment thread: %w", err)
+	}
+
+	return threadID, nil
+}
+
+func (s *PullRequestPostgresStorage) AddCommentTx(ctx context.Context, tx pgx.Tx, comment models.Comment) (int64, error) {
+	query := `
+		INSERT INTO pr_comments (thread_id, author_id, body)
+		VALUES ($1, $2, $3)
+		RETURNING comment_id
+	`
+
+	var row pgx.Row
+	if tx != nil {
+		row = tx.QueryRow(ctx, query, comment.ThreadID, comment.AuthorID, comment.Body)
+	} else {
+		row = s.pool.QueryRow(ctx, query, comment.ThreadID, comment.AuthorID, comment.Body)
+	}
+
+	var commentID int64
+	if err := row.Scan(&commentID); err != nil {
+		return 0, fmt.Errorf("failed to add comment: %w", err)
+	}
+
+	return commentID, nil
+}
+
+// SetThreadResolvedTx помечает ветку решенной (userID - кто решил) или снова открывает ее
+func (s *PullRequestPostgresStorage) SetThreadResolvedTx(ctx context.Context, tx pgx.Tx, threadID int64, resolved bool, userID string) error {
+	query := `
+		UPDATE pr_comment_threads
+		SET resolved = $1,
+			resolved_by = CASE WHEN $1 THEN $2 ELSE '' END,
+			resolved_at = CASE WHEN $1 THEN NOW() ELSE NULL END
+		WHERE thread_id = $3
+	`
+
+	var result pgconn.CommandTag
+	var err error
+	if tx != nil {
+		result, err = tx.Exec(ctx, query, resolved, userID, threadID)
+	} else {
+		result, err = s.pool.Exec(ctx, query, resolved, userID, threadID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update comment thread: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *PullRequestPostgresStorage) GetCommentThreadTx(ctx context.Context, tx pgx.Tx, threadID int64) (*models.CommentThread, error) {
+	threads, err := s.getCommentThreadsTx(ctx, tx, "thread_id", threadID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(threads) == 0 {
+		return nil, models.ErrNotFound
+	}
+
+	return &threads[0], nil
+}
+
+func (s *PullRequestPostgresStorage) GetCommentThreadsTx(ctx context.Context, tx pgx.Tx, prID string) ([]models.CommentThread, error) {
+	return s.getCommentThreadsTx(ctx, tx, "pull_request_id", prID)
+}
+
+// getCommentThreadsTx - ветки вместе с комментариями, column - колонка pr_comment_threads для фильтра
+func (s *PullRequestPostgresStorage) getCommentThreadsTx(ctx context.Context, tx pgx.Tx, column string, value any) ([]models.CommentThread, error) {
+	threadsQuery := fmt.Sprintf(`
+		SELECT thread_id, pull_request_id, author_id, file_path, line,
+			resolved, resolved_by, resolved_at, created_at
+		FROM pr_comment_threads
+		WHERE %s = $1
+		ORDER BY thread_id
+	`, column)
+
+	var rows pgx.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.Query(ctx, threadsQuery, value)
+	} else {
+		rows, err = s.pool.Query(ctx, threadsQuery, value)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query comment threads: %w", err)
+	}
+	defer rows.Close()
+
+	threads := []models.CommentThread{}
+	byID := map[int64]int{}
+	for rows.Next() {
+		var thread models.CommentThread
+		err := rows.Scan(
+			&thread.ThreadID,
+			&thread.PullRequestID,
+			&thread.AuthorID,
+			&thread.FilePath,
+			&thread.Line,
+			&thread.Resolved,
+			&thread.ResolvedBy,
+			&thread.ResolvedAt,
+			&thread.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan comment thread: %w", err)
+		}
+		thread.Comments = []models.Comment{}
+		byID[thread.ThreadID] = len(threads)
+		threads = append(threads, thread)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating comment threads: %w", err)
+	}
+	rows.Close()
+
+	if len(threads) == 0 {
+		return threads, nil
+	}
+
+	commentsQuery := fmt.Sprintf(`
+		SELECT c.comment_id, c.thread_id, c.author_id, c.body, c.created_at
+		FROM pr_comments c
+		JOIN pr_comment_threads t ON t.thread_id = c.thread_id
+		WHERE t.%s = $1
+		ORDER BY c.comment_id
+	`, column)
+
+	if tx != nil {
+		rows, err = tx.Query(ctx, commentsQuery, value)
+	} else {
+		rows, err = s.pool.Query(ctx, commentsQuery, value)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query comments: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var comment models.Comment
+		err := rows.Scan(
+			&comment.CommentID,
+			&comment.ThreadID,
+			&comment.AuthorID,
+			&comment.Body,
+			&comment.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan comment: %w", err)
+		}
+		i := byID[comment.ThreadID]
+		threads[i].Comments = append(threads[i].Comments, comment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating comments: %w", err)
+	}
+
+	return threads, nil
+}
+
+func (s *PullRequestPostgresStorage) CountUnresolvedThreadsTx(ctx context.Context, tx pgx.Tx, prID string) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM pr_comment_threads
+		WHERE pull_request_id = $1 AND NOT resolved
+	`
+
+	var row pgx.Row
+	if tx != nil {
+		row = tx.QueryRow(ctx, query, prID)
+	} else {
+		row = s.pool.QueryRow(ctx, query, prID)
+	}
+
+	var count int
+	if err := row.Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count unresolved threads: %w", err)
+	}
+
+	return count, nil
+}
+
+// SetPRDependenciesTx заменяет родителей PR на parents
+func (s *PullRequestPostgresStorage) SetPRDependenciesTx(ctx context.Context, tx pgx.Tx, prID string, parents []string) error {
+	deleteQuery := `DELETE FROM pull_request_dependencies WHERE pull_request_id = $1`
+	insertQuery := `
+		INSERT INTO pull_request_dependencies (pull_request_id, parent_id)
+		SELECT $1, unnest($2::text[])
+	`
+
+	var err error
+	if tx != nil {
+		_, err = tx.Exec(ctx, deleteQuery, prID)
+	} else {
+		_, err = s.pool.Exec(ctx, deleteQuery, prID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to clear PR dependencies: %w", err)
+	}
+
+	if tx != nil {
+		_, err = tx.Exec(ctx, insertQuery, prID, nonNilSlice(parents))
+	} else {
+		_, err = s.pool.Exec(ctx, insertQuery, prID, nonNilSlice(parents))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to add PR dependencies: %w", err)
+	}
+
+	return nil
+}
+
+// GetPRStackTx возвращает все PR, связанные с prID зависимостями в любую сторону
+// (родители, дети, родители детей и т.д.), включая сам prID. Порядок - по created_at,
+// порядок merge строит сервис
+func (s *PullRequestPostgresStorage) GetPRStackTx(ctx context.Context, tx pgx.Tx, prID string) ([]models.PullRequest, error) {
+	query := `
+		WITH RECURSIVE stack(id) AS (
+			SELECT $1::text
+			UNION
+			SELECT CASE WHEN d.pull_request_id = stack.id THEN d.parent_id ELSE d.pull_request_id END
+			FROM pull_request_dependencies d
+			JOIN stack ON d.pull_request_id = stack.id OR d.parent_id = stack.id
+		)
+		SELECT ` + prColumns + `
+		FROM pull_requests
+		WHERE pull_request_id IN (SELECT id FROM stack)
+		ORDER BY created_at, pull_request_id
+	`
+
+	var rows pgx.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.Query(ctx, query, prID)
+	} else {
+		rows, err = s.pool.Query(ctx, query, prID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query PR stack: %w", err)
+	}
+	defer rows.Close()
+
+	prs := []models.PullRequest{}
+	for rows.Next() {
+		pr, err := scanPR(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan PR: %w", err)
+		}
+		prs = append(prs, *pr)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating PR stack: %w", err)
+	}
+
+	return prs, nil
+}
+
+func (s *PullRequestPostgresStorage) PRBeginTx(ctx context.Context) (pgx.Tx, error) {
+	return s.pool.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:   pgx.Serializable,
+		AccessMode: pgx.ReadWrite,
+	})
+}
+
+func (s *PullRequestPostgresStorage) checkPRExistsTx(ctx context.Context, tx pgx.Tx, prID string) (bool, error) {
+	var exists bool
+
+	var row pgx.Row
+	if tx != nil {
+		row = tx.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM pull_requests WHERE pull_request_id = $1)", prID)
+	} else {
+		row = s.pool.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM pull_requests WHERE pull_request_id = $1)", prID)
+	}
+
+	err := row.Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check PR existence: %w", err)
+	}
+
+	return exists, nil
+}
+
+// nonNilSlice - pgx пишет nil-слайс как NULL, а колонки-массивы у нас NOT NULL
+func nonNilSlice(items []string) []string {
+	if items == nil {
+		return []string{}
+	}
+	return items
 }
@@ -49,11 +49,136 @@ func setupTestPRDB(t *testing.T) *pgxpool.Pool {
 		CREATE TABLE IF NOT EXISTS pull_requests (
 			pull_request_id TEXT PRIMARY KEY,
 			pull_request_name TEXT NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
 			author_id TEXT NOT NULL,
 			status TEXT NOT NULL,
 			assigned_reviewers TEXT[],
+			target_reviewers INT NOT NULL DEFAULT 2,
+			cross_team_reviewers TEXT[] NOT NULL DEFAULT '{}',
+			labels TEXT[] NOT NULL DEFAULT '{}',
+			repository TEXT,
+			team_name TEXT,
+			number INT,
+			version INT NOT NULL DEFAULT 1,
+			stale_at TIMESTAMPTZ,
 			created_at TIMESTAMP WITH TIME ZONE NOT NULL,
-			merged_at TIMESTAMP WITH TIME ZONE
+			merged_at TIMESTAMP WITH TIME ZONE,
+			closed_at TIMESTAMP WITH TIME ZONE,
+			UNIQUE (repository, number)
+		)
+	`)
+	require.NoError(t, err)
+
+	_, err = pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS pull_request_dependencies (
+			pull_request_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+			parent_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+			PRIMARY KEY (pull_request_id, parent_id)
+		)
+	`)
+	require.NoError(t, err)
+
+	_, err = pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS pull_request_reviews (
+			pull_request_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+			reviewer_id TEXT NOT NULL,
+			state TEXT NOT NULL DEFAULT 'PENDING',
+			body TEXT NOT NULL DEFAULT '',
+			requested_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			submitted_at TIMESTAMPTZ,
+			overdue_at TIMESTAMPTZ,
+			PRIMARY KEY (pull_request_id, reviewer_id)
+		)
+	`)
+	require.NoError(t, err)
+
+	_, err = pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS pull_request_history (
+			history_id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+			pull_request_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+			event TEXT NOT NULL,
+			from_status TEXT NOT NULL DEFAULT '',
+			to_status TEXT NOT NULL DEFAULT '',
+			actor TEXT NOT NULL DEFAULT '',
+			details JSONB NOT NULL DEFAULT '{}',
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)
+	`)
+	require.NoError(t, err)
+
+	_, err = pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS pr_assignment_decisions (
+			decision_id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+			pull_request_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+			reviewer_id TEXT NOT NULL,
+			action TEXT NOT NULL,
+			rule TEXT NOT NULL,
+			team_name TEXT NOT NULL,
+			strategy TEXT NOT NULL,
+			replaced_user_id TEXT NOT NULL DEFAULT '',
+			considered JSONB NOT NULL DEFAULT '[]',
+			excluded JSONB NOT NULL DEFAULT '[]',
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)
+	`)
+	require.NoError(t, err)
+
+	_, err = pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS pr_comment_threads (
+			thread_id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+			pull_request_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+			author_id TEXT NOT NULL,
+			file_path TEXT NOT NULL DEFAULT '',
+			line INT CHECK (line > 0),
+			resolved BOOLEAN NOT NULL DEFAULT false,
+			resolved_by TEXT NOT NULL DEFAULT '',
+			resolved_at TIMESTAMPTZ,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+
+		CREATE TABLE IF NOT EXISTS pr_comments (
+			comment_id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+			thread_id BIGINT NOT NULL REFERENCES pr_comment_threads(thread_id) ON DELETE CASCADE,
+			author_id TEXT NOT NULL,
+			body TEXT NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)
+	`)
+	require.NoError(t, err)
+
+	_, err = pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS users (
+			user_id TEXT PRIMARY KEY
+		)
+	`)
+	require.NoError(t, err)
+
+	_, err = pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS team_memberships (
+			user_id TEXT NOT NULL,
+			team_name TEXT NOT NULL,
+			is_primary BOOLEAN NOT NULL DEFAULT false,
+			joined_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (user_id, team_name)
+		)
+	`)
+	require.NoError(t, err)
+
+	_, err = pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS repositories (
+			name TEXT PRIMARY KEY,
+			default_team TEXT
+		)
+	`)
+	require.NoError(t, err)
+
+	_, err = pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS teams (
+			name TEXT PRIMARY KEY,
+			stale_enabled BOOLEAN NOT NULL DEFAULT false,
+			stale_after_days INT NOT NULL DEFAULT 14,
+			stale_close_after_days INT NOT NULL DEFAULT 7,
+			review_sla_hours INT NOT NULL DEFAULT 0
 		)
 	`)
 	require.NoError(t, err)
@@ -129,10 +254,658 @@ func TestPullRequestPostgresStorage_Integration(t *testing.T) {
 		assert.NotNil(t, mergedPR.MergedAt)
 		assert.WithinDuration(t, time.Now().UTC(), *mergedPR.MergedAt, 5*time.Second)
 
+		err = storage.UpdatePRReviewersTx(ctx, tx, testPR.PullRequestID, []string{"user3"}, nil)
+		assert.ErrorIs(t, err, models.ErrPRMerged)
+
 		err = storage.MergePRTx(ctx, tx, testPR.PullRequestID)
 		require.NoError(t, err)
 
 		err = tx.Commit(ctx)
 		require.NoError(t, err)
 	})
+	t.Run("Open review load ignores merged PRs", func(t *testing.T) {
+		tx, err := storage.PRBeginTx(ctx)
+		require.NoError(t, err)
+		defer tx.Rollback(ctx)
+
+		load, err := storage.GetOpenReviewLoadTx(ctx, tx, []string{"user2", "user3", "user4"})
+		require.NoError(t, err)
+		assert.Equal(t, 1, load["user2"])
+		assert.Equal(t, 1, load["user3"])
+		assert.Equal(t, 0, load["user4"])
+		assert.Len(t, load, 3)
+	})
+
+	t.Run("Understaffed PRs", func(t *testing.T) {
+		tx, err := storage.PRBeginTx(ctx)
+		require.NoError(t, err)
+		defer tx.Rollback(ctx)
+
+		_, err = tx.Exec(ctx, `INSERT INTO team_memberships (user_id, team_name, is_primary) VALUES ('author1', 'backend', true), ('author2', 'frontend', true)`)
+		require.NoError(t, err)
+
+		for _, pr := range []models.PullRequest{
+			{PullRequestID: "PR-SHORT-1", AuthorID: "author1", AssignedReviewers: []string{"user2"}, TargetReviewers: 2},
+			{PullRequestID: "PR-SHORT-2", AuthorID: "author2", AssignedReviewers: []string{}, TargetReviewers: 1},
+			{PullRequestID: "PR-FULL", AuthorID: "author1", AssignedReviewers: []string{"user2", "user3"}, TargetReviewers: 2},
+		} {
+			pr.PullRequestName = pr.PullRequestID
+			pr.Status = "OPEN"
+			pr.CreatedAt = time.Now().UTC()
+			require.NoError(t, storage.CreatePRTx(ctx, tx, pr))
+		}
+
+		all, err := storage.GetUnderstaffedPRsTx(ctx, tx, "")
+		require.NoError(t, err)
+		require.Len(t, all, 2)
+		assert.Equal(t, "PR-SHORT-1", all[0].PullRequestID)
+		assert.Equal(t, "PR-SHORT-2", all[1].PullRequestID)
+
+		backend, err := storage.GetUnderstaffedPRsTx(ctx, tx, "backend")
+		require.NoError(t, err)
+		require.Len(t, backend, 1)
+		assert.Equal(t, "PR-SHORT-1", backend[0].PullRequestID)
+	})
+
+	t.Run("Status transitions", func(t *testing.T) {
+		tx, err := storage.PRBeginTx(ctx)
+		require.NoError(t, err)
+		defer tx.Rollback(ctx)
+
+		err = storage.CreatePRTx(ctx, tx, models.PullRequest{
+			PullRequestID:     "PR-LIFECYCLE",
+			PullRequestName:   "Lifecycle",
+			AuthorID:          "user1",
+			Status:            models.PRStatusOpen,
+			AssignedReviewers: []string{"user5"},
+		})
+		require.NoError(t, err)
+
+		err = storage.UpdatePRStatusTx(ctx, tx, "PR-LIFECYCLE", models.PRStatusOpen, models.PRStatusClosed)
+		require.NoError(t, err)
+
+		closed, err := storage.GetPRByIDTx(ctx, tx, "PR-LIFECYCLE")
+		require.NoError(t, err)
+		assert.Equal(t, models.PRStatusClosed, closed.Status)
+		assert.NotNil(t, closed.ClosedAt)
+
+		load, err := storage.GetOpenReviewLoadTx(ctx, tx, []string{"user5"})
+		require.NoError(t, err)
+		assert.Equal(t, 0, load["user5"])
+
+		err = storage.UpdatePRReviewersTx(ctx, tx, "PR-LIFECYCLE", []string{"user6"}, nil)
+		assert.ErrorIs(t, err, models.ErrPRNotOpen)
+
+		err = storage.UpdatePRReviewersTx(ctx, tx, "PR-MISSING", []string{"user6"}, nil)
+		assert.ErrorIs(t, err, models.ErrNotFound)
+
+		err = storage.UpdatePRStatusTx(ctx, tx, "PR-LIFECYCLE", models.PRStatusOpen, models.PRStatusMerged)
+		assert.ErrorIs(t, err, models.ErrInvalidTransition)
+
+		err = storage.UpdatePRStatusTx(ctx, tx, "PR-MISSING", models.PRStatusOpen, models.PRStatusClosed)
+		assert.ErrorIs(t, err, models.ErrNotFound)
+
+		err = storage.UpdatePRStatusTx(ctx, tx, "PR-LIFECYCLE", models.PRStatusClosed, models.PRStatusReopened)
+		require.NoError(t, err)
+
+		reopened, err := storage.GetPRByIDTx(ctx, tx, "PR-LIFECYCLE")
+		require.NoError(t, err)
+		assert.Equal(t, models.PRStatusReopened, reopened.Status)
+		assert.Nil(t, reopened.ClosedAt)
+
+		load, err = storage.GetOpenReviewLoadTx(ctx, tx, []string{"user5"})
+		require.NoError(t, err)
+		assert.Equal(t, 1, load["user5"])
+	})
+
+	t.Run("Open PRs by reviewer", func(t *testing.T) {
+		tx, err := storage.PRBeginTx(ctx)
+		require.NoError(t, err)
+		defer tx.Rollback(ctx)
+
+		for _, pr := range []models.PullRequest{
+			{PullRequestID: "PR-REV-OPEN", Status: models.PRStatusOpen, AssignedReviewers: []string{"user7", "user8"}},
+			{PullRequestID: "PR-REV-DRAFT", Status: models.PRStatusDraft, AssignedReviewers: []string{"user7"}},
+			{PullRequestID: "PR-REV-REOPENED", Status: models.PRStatusOpen, AssignedReviewers: []string{"user7"}},
+			{PullRequestID: "PR-REV-MERGED", Status: models.PRStatusOpen, AssignedReviewers: []string{"user7"}},
+			{PullRequestID: "PR-REV-OTHER", Status: models.PRStatusOpen, AssignedReviewers: []string{"user8"}},
+		} {
+			pr.PullRequestName = pr.PullRequestID
+			pr.AuthorID = "user1"
+			require.NoError(t, storage.CreatePRTx(ctx, tx, pr))
+		}
+
+		require.NoError(t, storage.UpdatePRStatusTx(ctx, tx, "PR-REV-REOPENED", models.PRStatusOpen, models.PRStatusClosed))
+		require.NoError(t, storage.UpdatePRStatusTx(ctx, tx, "PR-REV-REOPENED", models.PRStatusClosed, models.PRStatusReopened))
+		require.NoError(t, storage.MergePRTx(ctx, tx, "PR-REV-MERGED"))
+
+		prs, err := storage.GetOpenPRsByReviewerTx(ctx, tx, "user7")
+		require.NoError(t, err)
+		require.Len(t, prs, 2)
+		assert.Equal(t, "PR-REV-OPEN", prs[0].PullRequestID)
+		assert.Equal(t, []string{"user7", "user8"}, prs[0].AssignedReviewers)
+		assert.Equal(t, "PR-REV-REOPENED", prs[1].PullRequestID)
+		assert.Equal(t, models.PRStatusReopened, prs[1].Status)
+
+		prs, err = storage.GetOpenPRsByReviewerTx(ctx, tx, "user-without-reviews")
+		require.NoError(t, err)
+		assert.Empty(t, prs)
+	})
+
+	t.Run("Stale candidates", func(t *testing.T) {
+		tx, err := storage.PRBeginTx(ctx)
+		require.NoError(t, err)
+		defer tx.Rollback(ctx)
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO teams (name, stale_enabled, stale_after_days) VALUES ('stale-on', true, 3), ('stale-off', false, 3);
+			INSERT INTO team_memberships (user_id, team_name, is_primary) VALUES ('author-on', 'stale-on', true), ('author-off', 'stale-off', true);
+		`)
+		require.NoError(t, err)
+
+		old := time.Now().UTC().AddDate(0, 0, -10)
+		for _, pr := range []models.PullRequest{
+			{PullRequestID: "PR-STALE-ON", AuthorID: "author-on"},
+			{PullRequestID: "PR-STALE-OFF", AuthorID: "author-off"},
+		} {
+			pr.PullRequestName = pr.PullRequestID
+			pr.Status = models.PRStatusOpen
+			pr.AssignedReviewers = []string{}
+			require.NoError(t, storage.CreatePRTx(ctx, tx, pr))
+		}
+		_, err = tx.Exec(ctx, `UPDATE pull_requests SET created_at = $1 WHERE pull_request_id LIKE 'PR-STALE-%'`, old)
+		require.NoError(t, err)
+
+		candidates, err := storage.GetStaleCandidatesTx(ctx, tx, "stale-on")
+		require.NoError(t, err)
+		require.Len(t, candidates, 1)
+		assert.Equal(t, "PR-STALE-ON", candidates[0].PullRequestID)
+		assert.Equal(t, 3, candidates[0].StaleAfterDays)
+		assert.WithinDuration(t, old, candidates[0].LastActivityAt, time.Second)
+		assert.Nil(t, candidates[0].StaleAt)
+
+		staleAt := time.Now().UTC()
+		err = storage.SetPRStaleTx(ctx, tx, "PR-STALE-ON", &staleAt)
+		require.NoError(t, err)
+		err = storage.AddPRHistoryTx(ctx, tx, models.PRHistoryEntry{
+			PullRequestID: "PR-STALE-ON",
+			Event:         models.PREventStale,
+		})
+		require.NoError(t, err)
+
+		candidates, err = storage.GetStaleCandidatesTx(ctx, tx, "stale-on")
+		require.NoError(t, err)
+		require.Len(t, candidates, 1)
+		assert.True(t, candidates[0].IsStale())
+		assert.WithinDuration(t, old, candidates[0].LastActivityAt, time.Second)
+
+		candidate, err := storage.GetStaleCandidateTx(ctx, tx, "PR-STALE-ON")
+		require.NoError(t, err)
+		assert.True(t, candidate.IsStale())
+		_, err = storage.GetStaleCandidateTx(ctx, tx, "PR-STALE-OFF")
+		assert.ErrorIs(t, err, models.ErrNotFound)
+
+		// активность после отметки снимает устаревание
+		markedAt := old.AddDate(0, 0, 5)
+		err = storage.SetPRStaleTx(ctx, tx, "PR-STALE-ON", &markedAt)
+		require.NoError(t, err)
+		err = storage.AddPRHistoryTx(ctx, tx, models.PRHistoryEntry{
+			PullRequestID: "PR-STALE-ON",
+			Event:         models.PREventEdited,
+		})
+		require.NoError(t, err)
+		candidate, err = storage.GetStaleCandidateTx(ctx, tx, "PR-STALE-ON")
+		require.NoError(t, err)
+		assert.False(t, candidate.IsStale())
+
+		err = storage.UpdatePRStatusTx(ctx, tx, "PR-STALE-ON", models.PRStatusOpen, models.PRStatusClosed)
+		require.NoError(t, err)
+		err = storage.UpdatePRStatusTx(ctx, tx, "PR-STALE-ON", models.PRStatusClosed, models.PRStatusReopened)
+		require.NoError(t, err)
+
+		candidates, err = storage.GetStaleCandidatesTx(ctx, tx, "stale-on")
+		require.NoError(t, err)
+		require.Len(t, candidates, 1)
+		assert.Nil(t, candidates[0].StaleAt)
+
+		err = storage.SetPRStaleTx(ctx, tx, "PR-MISSING", nil)
+		assert.ErrorIs(t, err, models.ErrNotFound)
+	})
+
+	t.Run("Reviews follow assigned reviewers", func(t *testing.T) {
+		tx, err := storage.PRBeginTx(ctx)
+		require.NoError(t, err)
+		defer tx.Rollback(ctx)
+
+		err = storage.CreatePRTx(ctx, tx, models.PullRequest{
+			PullRequestID:     "PR-REVIEWS",
+			PullRequestName:   "Reviews",
+			AuthorID:          "user1",
+			Status:            models.PRStatusOpen,
+			AssignedReviewers: []string{"user6", "user7"},
+		})
+		require.NoError(t, err)
+
+		err = storage.SubmitReviewTx(ctx, tx, models.SubmitReviewRequest{
+			PullRequestID: "PR-REVIEWS",
+			ReviewerID:    "user6",
+			State:         models.ReviewStateApproved,
+			Body:          "LGTM",
+		})
+		require.NoError(t, err)
+
+		err = storage.SubmitReviewTx(ctx, tx, models.SubmitReviewRequest{
+			PullRequestID: "PR-REVIEWS",
+			ReviewerID:    "user8",
+			State:         models.ReviewStateApproved,
+		})
+		assert.ErrorIs(t, err, models.ErrNotAssigned)
+
+		owed, err := storage.GetPRsByReviewerTx(ctx, tx, "user6", "")
+		require.NoError(t, err)
+		require.Len(t, owed, 1)
+		assert.Equal(t, models.ReviewStateApproved, owed[0].ReviewState)
+		assert.False(t, owed[0].ReviewOwed)
+
+		err = storage.UpdatePRReviewersTx(ctx, tx, "PR-REVIEWS", []string{"user6", "user8"}, nil)
+		require.NoError(t, err)
+
+		reviews, err := storage.GetPRReviewsTx(ctx, tx, "PR-REVIEWS")
+		require.NoError(t, err)
+		require.Len(t, reviews, 2)
+		assert.Equal(t, "user6", reviews[0].ReviewerID)
+		assert.Equal(t, models.ReviewStateApproved, reviews[0].State)
+		assert.NotNil(t, reviews[0].SubmittedAt)
+		assert.Equal(t, "user8", reviews[1].ReviewerID)
+		assert.Equal(t, models.ReviewStatePending, reviews[1].State)
+
+		err = storage.ResetReviewTx(ctx, tx, "PR-REVIEWS", "user6")
+		require.NoError(t, err)
+
+		owed, err = storage.GetPRsByReviewerTx(ctx, tx, "user6", "")
+		require.NoError(t, err)
+		require.Len(t, owed, 1)
+		assert.Equal(t, models.ReviewStatePending, owed[0].ReviewState)
+		assert.True(t, owed[0].ReviewOwed)
+	})
+
+	t.Run("Overdue reviews", func(t *testing.T) {
+		tx, err := storage.PRBeginTx(ctx)
+		require.NoError(t, err)
+		defer tx.Rollback(ctx)
+
+		_, err = tx.Exec(ctx, `INSERT INTO teams (name, review_sla_hours) VALUES ('sla-on', 8), ('sla-off', 0)`)
+		require.NoError(t, err)
+
+		err = storage.CreatePRTx(ctx, tx, models.PullRequest{
+			PullRequestID:     "PR-SLA",
+			PullRequestName:   "SLA",
+			AuthorID:          "user1",
+			Status:            models.PRStatusOpen,
+			AssignedReviewers: []string{"user6", "user7"},
+			TeamName:          "sla-on",
+		})
+		require.NoError(t, err)
+
+		// в PR команды без SLA ревью не просрочиваются
+		err = storage.CreatePRTx(ctx, tx, models.PullRequest{
+			PullRequestID:     "PR-NO-SLA",
+			PullRequestName:   "No SLA",
+			AuthorID:          "user1",
+			Status:            models.PRStatusOpen,
+			AssignedReviewers: []string{"user6"},
+			TeamName:          "sla-off",
+		})
+		require.NoError(t, err)
+
+		err = storage.SubmitReviewTx(ctx, tx, models.SubmitReviewRequest{
+			PullRequestID: "PR-SLA",
+			ReviewerID:    "user7",
+			State:         models.ReviewStateApproved,
+		})
+		require.NoError(t, err)
+
+		pending, err := storage.GetPendingReviewsTx(ctx, tx)
+		require.NoError(t, err)
+		require.Len(t, pending, 1)
+		assert.Equal(t, "PR-SLA", pending[0].PullRequestID)
+		assert.Equal(t, "user6", pending[0].ReviewerID)
+
+		err = storage.MarkReviewOverdueTx(ctx, tx, "PR-SLA", "user6")
+		require.NoError(t, err)
+		err = storage.MarkReviewOverdueTx(ctx, tx, "PR-SLA", "user6")
+		assert.ErrorIs(t, err, models.ErrNotAssigned)
+		err = storage.MarkReviewOverdueTx(ctx, tx, "PR-SLA", "user7")
+		assert.ErrorIs(t, err, models.ErrNotAssigned)
+
+		count, err := storage.CountOverdueReviewsTx(ctx, tx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+
+		prs, err := storage.GetPRsByReviewerTx(ctx, tx, "user6", "")
+		require.NoError(t, err)
+		for _, pr := range prs {
+			if pr.PullRequestID == "PR-SLA" {
+				assert.True(t, pr.ReviewOverdue)
+			}
+		}
+
+		err = storage.ResetReviewTx(ctx, tx, "PR-SLA", "user6")
+		require.NoError(t, err)
+
+		count, err = storage.CountOverdueReviewsTx(ctx, tx)
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+
+	t.Run("PR history", func(t *testing.T) {
+		tx, err := storage.PRBeginTx(ctx)
+		require.NoError(t, err)
+		defer tx.Rollback(ctx)
+
+		err = storage.AddPRHistoryTx(ctx, tx, models.PRHistoryEntry{
+			PullRequestID: "PR-MERGE-TEST",
+			Event:         models.PREventMerged,
+			FromStatus:    models.PRStatusOpen,
+			ToStatus:      models.PRStatusMerged,
+			Actor:         "admin",
+			Details:       map[string]any{"override": true, "reason": "hotfix"},
+		})
+		require.NoError(t, err)
+
+		err = storage.AddPRHistoryTx(ctx, tx, models.PRHistoryEntry{
+			PullRequestID: "PR-MERGE-TEST",
+			Event:         models.PREventReopened,
+		})
+		require.NoError(t, err)
+
+		history, err := storage.GetPRHistoryTx(ctx, tx, "PR-MERGE-TEST")
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		assert.Equal(t, models.PREventMerged, history[0].Event)
+		assert.Equal(t, "admin", history[0].Actor)
+		assert.Equal(t, true, history[0].Details["override"])
+		assert.Equal(t, "hotfix", history[0].Details["reason"])
+		assert.Empty(t, history[1].Details)
+	})
+
+	t.Run("Update metadata with version check", func(t *testing.T) {
+		tx, err := storage.PRBeginTx(ctx)
+		require.NoError(t, err)
+		defer tx.Rollback(ctx)
+
+		pr, err := storage.GetPRByIDTx(ctx, tx, "PR-001")
+		require.NoError(t, err)
+		assert.Equal(t, 1, pr.Version)
+		assert.Equal(t, "", pr.Description)
+
+		pr.PullRequestName = "Renamed feature"
+		pr.Description = "Adds the feature"
+		pr.Labels = []string{"backend"}
+		err = storage.UpdatePRMetadataTx(ctx, tx, *pr, 1)
+		require.NoError(t, err)
+
+		updated, err := storage.GetPRByIDTx(ctx, tx, "PR-001")
+		require.NoError(t, err)
+		assert.Equal(t, 2, updated.Version)
+		assert.Equal(t, "Renamed feature", updated.PullRequestName)
+		assert.Equal(t, "Adds the feature", updated.Description)
+		assert.Equal(t, []string{"backend"}, updated.Labels)
+
+		// Вторая правка по устаревшей версии не должна перетереть первую
+		pr.PullRequestName = "Stale rename"
+		err = storage.UpdatePRMetadataTx(ctx, tx, *pr, 1)
+		assert.ErrorIs(t, err, models.ErrVersionConflict)
+
+		current, err := storage.GetPRByIDTx(ctx, tx, "PR-001")
+		require.NoError(t, err)
+		assert.Equal(t, "Renamed feature", current.PullRequestName)
+	})
+
+	t.Run("Dependencies and stack", func(t *testing.T) {
+		tx, err := storage.PRBeginTx(ctx)
+		require.NoError(t, err)
+		defer tx.Rollback(ctx)
+
+		for _, id := range []string{"STACK-BASE", "STACK-MID", "STACK-TOP", "STACK-OTHER"} {
+			err = storage.CreatePRTx(ctx, tx, models.PullRequest{
+				PullRequestID:   id,
+				PullRequestName: id,
+				AuthorID:        "user1",
+				Status:          models.PRStatusOpen,
+			})
+			require.NoError(t, err)
+		}
+
+		err = storage.SetPRDependenciesTx(ctx, tx, "STACK-MID", []string{"STACK-BASE"})
+		require.NoError(t, err)
+		err = storage.SetPRDependenciesTx(ctx, tx, "STACK-TOP", []string{"STACK-MID", "STACK-BASE"})
+		require.NoError(t, err)
+
+		top, err := storage.GetPRByIDTx(ctx, tx, "STACK-TOP")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"STACK-BASE", "STACK-MID"}, top.DependsOn)
+
+		base, err := storage.GetPRByIDTx(ctx, tx, "STACK-BASE")
+		require.NoError(t, err)
+		assert.Empty(t, base.DependsOn)
+
+		// Стек находится с любого PR в нем, посторонние PR в него не попадают
+		stack, err := storage.GetPRStackTx(ctx, tx, "STACK-MID")
+		require.NoError(t, err)
+		ids := []string{}
+		for _, pr := range stack {
+			ids = append(ids, pr.PullRequestID)
+		}
+		assert.ElementsMatch(t, []string{"STACK-BASE", "STACK-MID", "STACK-TOP"}, ids)
+
+		err = storage.SetPRDependenciesTx(ctx, tx, "STACK-TOP", nil)
+		require.NoError(t, err)
+
+		stack, err = storage.GetPRStackTx(ctx, tx, "STACK-TOP")
+		require.NoError(t, err)
+		require.Len(t, stack, 1)
+		assert.Empty(t, stack[0].DependsOn)
+	})
+
+	t.Run("Repository PRs", func(t *testing.T) {
+		tx, err := storage.PRBeginTx(ctx)
+		require.NoError(t, err)
+		defer tx.Rollback(ctx)
+
+		_, err = tx.Exec(ctx, `INSERT INTO repositories (name, default_team) VALUES ('api', 'platform'), ('web', NULL)`)
+		require.NoError(t, err)
+		_, err = tx.Exec(ctx, `INSERT INTO team_memberships (user_id, team_name, is_primary) VALUES ('author3', 'backend', true)`)
+		require.NoError(t, err)
+
+		for _, repo := range []string{"api", "web"} {
+			err = storage.CreatePRTx(ctx, tx, models.PullRequest{
+				PullRequestID:     models.RepositoryPRID(repo, 7),
+				PullRequestName:   "Same number",
+				AuthorID:          "author3",
+				Status:            models.PRStatusOpen,
+				AssignedReviewers: []string{"user9"},
+				TargetReviewers:   2,
+				Repository:        repo,
+				Number:            7,
+			})
+			require.NoError(t, err)
+		}
+
+		pr, err := storage.GetPRByIDTx(ctx, tx, "api#7")
+		require.NoError(t, err)
+		assert.Equal(t, "api", pr.Repository)
+		assert.Equal(t, 7, pr.Number)
+
+		legacy, err := storage.GetPRByIDTx(ctx, tx, "PR-001")
+		require.NoError(t, err)
+		assert.Equal(t, "", legacy.Repository)
+		assert.Equal(t, 0, legacy.Number)
+
+		all, err := storage.GetPRsByReviewerTx(ctx, tx, "user9", "")
+		require.NoError(t, err)
+		assert.Len(t, all, 2)
+
+		web, err := storage.GetPRsByReviewerTx(ctx, tx, "user9", "web")
+		require.NoError(t, err)
+		require.Len(t, web, 1)
+		assert.Equal(t, "web#7", web[0].PullRequestID)
+		assert.Equal(t, "web", web[0].Repository)
+
+		// PR в api принадлежит команде репозитория, в web - команде автора
+		platform, err := storage.GetUnderstaffedPRsTx(ctx, tx, "platform")
+		require.NoError(t, err)
+		require.Len(t, platform, 1)
+		assert.Equal(t, "api#7", platform[0].PullRequestID)
+
+		backend, err := storage.GetUnderstaffedPRsTx(ctx, tx, "backend")
+		require.NoError(t, err)
+		require.Len(t, backend, 1)
+		assert.Equal(t, "web#7", backend[0].PullRequestID)
+
+		// явно указанная команда PR важнее команды репозитория
+		err = storage.CreatePRTx(ctx, tx, models.PullRequest{
+			PullRequestID:   models.RepositoryPRID("api", 8),
+			PullRequestName: "Explicit team",
+			AuthorID:        "author3",
+			Status:          models.PRStatusOpen,
+			TargetReviewers: 1,
+			Repository:      "api",
+			Number:          8,
+			TeamName:        "frontend",
+		})
+		require.NoError(t, err)
+
+		explicit, err := storage.GetPRByIDTx(ctx, tx, "api#8")
+		require.NoError(t, err)
+		assert.Equal(t, "frontend", explicit.TeamName)
+
+		frontend, err := storage.GetUnderstaffedPRsTx(ctx, tx, "frontend")
+		require.NoError(t, err)
+		require.Len(t, frontend, 1)
+		assert.Equal(t, "api#8", frontend[0].PullRequestID)
+
+		err = storage.CreatePRTx(ctx, tx, models.PullRequest{
+			PullRequestID:   "api-duplicate",
+			PullRequestName: "Duplicate number",
+			AuthorID:        "author3",
+			Status:          models.PRStatusOpen,
+			Repository:      "api",
+			Number:          7,
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("Comment threads", func(t *testing.T) {
+		tx, err := storage.PRBeginTx(ctx)
+		require.NoError(t, err)
+		defer tx.Rollback(ctx)
+
+		line := 42
+		lineThread, err := storage.CreateCommentThreadTx(ctx, tx, models.CommentThread{
+			PullRequestID: "PR-001",
+			AuthorID:      "user2",
+			FilePath:      "internal/app/app.go",
+			Line:          &line,
+		})
+		require.NoError(t, err)
+		_, err = storage.AddCommentTx(ctx, tx, models.Comment{ThreadID: lineThread, AuthorID: "user2", Body: "nil check?"})
+		require.NoError(t, err)
+		_, err = storage.AddCommentTx(ctx, tx, models.Comment{ThreadID: lineThread, AuthorID: "user1", Body: "done"})
+		require.NoError(t, err)
+
+		prThread, err := storage.CreateCommentThreadTx(ctx, tx, models.CommentThread{PullRequestID: "PR-001", AuthorID: "user3"})
+		require.NoError(t, err)
+		_, err = storage.AddCommentTx(ctx, tx, models.Comment{ThreadID: prThread, AuthorID: "user3", Body: "LGTM overall"})
+		require.NoError(t, err)
+
+		count, err := storage.CountUnresolvedThreadsTx(ctx, tx, "PR-001")
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+
+		err = storage.SetThreadResolvedTx(ctx, tx, lineThread, true, "user2")
+		require.NoError(t, err)
+
+		threads, err := storage.GetCommentThreadsTx(ctx, tx, "PR-001")
+		require.NoError(t, err)
+		require.Len(t, threads, 2)
+		assert.True(t, threads[0].Resolved)
+		assert.Equal(t, "user2", threads[0].ResolvedBy)
+		assert.NotNil(t, threads[0].ResolvedAt)
+		require.NotNil(t, threads[0].Line)
+		assert.Equal(t, 42, *threads[0].Line)
+		require.Len(t, threads[0].Comments, 2)
+		assert.Equal(t, "done", threads[0].Comments[1].Body)
+		assert.Nil(t, threads[1].Line)
+		assert.Equal(t, "", threads[1].FilePath)
+		require.Len(t, threads[1].Comments, 1)
+
+		count, err = storage.CountUnresolvedThreadsTx(ctx, tx, "PR-001")
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+
+		err = storage.SetThreadResolvedTx(ctx, tx, lineThread, false, "user1")
+		require.NoError(t, err)
+
+		thread, err := storage.GetCommentThreadTx(ctx, tx, lineThread)
+		require.NoError(t, err)
+		assert.False(t, thread.Resolved)
+		assert.Equal(t, "", thread.ResolvedBy)
+		assert.Nil(t, thread.ResolvedAt)
+
+		err = storage.SetThreadResolvedTx(ctx, tx, 999999, true, "user1")
+		assert.ErrorIs(t, err, models.ErrNotFound)
+
+		_, err = storage.GetCommentThreadTx(ctx, tx, 999999)
+		assert.ErrorIs(t, err, models.ErrNotFound)
+	})
+
+	t.Run("Assignment decisions", func(t *testing.T) {
+		tx, err := storage.PRBeginTx(ctx)
+		require.NoError(t, err)
+		defer tx.Rollback(ctx)
+
+		err = storage.CreateAssignmentDecisionsTx(ctx, tx, []models.AssignmentDecision{
+			{
+				PullRequestID: "PR-001",
+				ReviewerID:    "user2",
+				Action:        models.AssignmentActionCreate,
+				Rule:          models.ReviewerSourceTeam,
+				TeamName:      "backend",
+				Strategy:      models.StrategyLeastLoaded,
+				Considered: []models.ConsideredCandidate{
+					{UserID: "user2", OpenReviews: 0, Rank: 1, Selected: true},
+					{UserID: "user4", OpenReviews: 3, Rank: 2},
+				},
+				Excluded: []models.CandidateExclusion{
+					{UserID: "user1", Reason: models.ExclusionAuthor},
+				},
+			},
+			{
+				PullRequestID:  "PR-001",
+				ReviewerID:     "user4",
+				Action:         models.AssignmentActionReassign,
+				Rule:           models.ReviewerSourceFallback,
+				TeamName:       "frontend",
+				Strategy:       models.StrategyFirstN,
+				ReplacedUserID: "user3",
+			},
+		})
+		require.NoError(t, err)
+
+		decisions, err := storage.GetAssignmentDecisionsTx(ctx, tx, "PR-001")
+		require.NoError(t, err)
+		require.Len(t, decisions, 2)
+
+		assert.Equal(t, "user2", decisions[0].ReviewerID)
+		assert.Equal(t, models.ReviewerSourceTeam, decisions[0].Rule)
+		require.Len(t, decisions[0].Considered, 2)
+		assert.True(t, decisions[0].Considered[0].Selected)
+		assert.Equal(t, 3, decisions[0].Considered[1].OpenReviews)
+		assert.Equal(t, []models.CandidateExclusion{{UserID: "user1", Reason: models.ExclusionAuthor}}, decisions[0].Excluded)
+
+		assert.Equal(t, "user3", decisions[1].ReplacedUserID)
+		assert.Empty(t, decisions[1].Considered)
+		assert.NotNil(t, decisions[1].Excluded)
+	})
 }
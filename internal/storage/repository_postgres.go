@@ -0,0 +1,117 @@
+package storage
+
+/*
+Основные функции:
+	1. Регистрация репозитория
+	2. Получение репозитория по имени
+	3. Изменение настроек репозитория (команда по умолчанию, стратегия, число ревьюеров)
+
+Фича - если Tx - nil, то используем просто pool
+*/
+
+import (
+	"context"
+	"fmt"
+	"subscription-budget/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type RepositoryPostgresStorage struct {
+	pool *pgxpool.Pool
+}
+
+func NewRepositoryPostgresStorage(pool *pgxpool.Pool) *RepositoryPostgresStorage {
+	return &RepositoryPostgresStorage{pool: pool}
+}
+
+func (s *RepositoryPostgresStorage) RepositoryBeginTx(ctx context.Context) (pgx.Tx, error) {
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:   pgx.Serializable,
+		AccessMode: pgx.ReadWrite,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	return tx, nil
+}
+
+func (s *RepositoryPostgresStorage) CreateRepositoryTx(ctx context.Context, tx pgx.Tx, repo models.Repository) error {
+	query := `
+		INSERT INTO repositories (name, default_team, reviewer_strategy, reviewer_count)
+		VALUES ($1, NULLIF($2, ''), $3, $4)
+		ON CONFLICT (name) DO NOTHING
+	`
+
+	var result pgconn.CommandTag
+	var err error
+	if tx != nil {
+		result, err = tx.Exec(ctx, query, repo.Name, repo.DefaultTeam, repo.ReviewerStrategy, repo.ReviewerCount)
+	} else {
+		result, err = s.pool.Exec(ctx, query, repo.Name, repo.DefaultTeam, repo.ReviewerStrategy, repo.ReviewerCount)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create repository: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return models.ErrRepositoryExists
+	}
+
+	return nil
+}
+
+func (s *RepositoryPostgresStorage) GetRepositoryTx(ctx context.Context, tx pgx.Tx, name string) (*models.Repository, error) {
+	query := `
+		SELECT name, COALESCE(default_team, ''), reviewer_strategy, reviewer_count, created_at
+		FROM repositories
+		WHERE name = $1
+	`
+
+	var row pgx.Row
+	if tx != nil {
+		row = tx.QueryRow(ctx, query, name)
+	} else {
+		row = s.pool.QueryRow(ctx, query, name)
+	}
+
+	var repo models.Repository
+	err := row.Scan(&repo.Name, &repo.DefaultTeam, &repo.ReviewerStrategy, &repo.ReviewerCount, &repo.CreatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get repository: %w", err)
+	}
+
+	return &repo, nil
+}
+
+func (s *RepositoryPostgresStorage) UpdateRepositoryTx(ctx context.Context, tx pgx.Tx, repo models.Repository) error {
+	query := `
+		UPDATE repositories
+		SET default_team = NULLIF($1, ''),
+			reviewer_strategy = $2,
+			reviewer_count = $3
+		WHERE name = $4
+	`
+
+	var result pgconn.CommandTag
+	var err error
+	if tx != nil {
+		result, err = tx.Exec(ctx, query, repo.DefaultTeam, repo.ReviewerStrategy, repo.ReviewerCount, repo.Name)
+	} else {
+		result, err = s.pool.Exec(ctx, query, repo.DefaultTeam, repo.ReviewerStrategy, repo.ReviewerCount, repo.Name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update repository: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package storage
+
+/*
+Тесты через создание контейнера с постгрес
+Проверка:
+	1. Регистрация и чтение репозитория
+	2. Повторная регистрация того же репозитория
+	3. Изменение и сброс настроек
+	4. Получение несуществующего репозитория
+*/
+import (
+	"context"
+	"subscription-budget/internal/models"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+func setupRepositoryTestDB(t *testing.T) *pgxpool.Pool {
+	ctx := context.Background()
+
+	container, err := postgres.Run(ctx,
+		"postgres:15-alpine",
+		postgres.WithDatabase("test_db"),
+		postgres.WithUsername("test_user"),
+		postgres.WithPassword("test_password"),
+		testcontainers.WithWaitStrategy(
+			wait.ForLog("database system is ready to accept connections").
+				WithOccurrence(2),
+		),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, container.Terminate(ctx))
+	})
+
+	connStr, err := container.ConnectionString(ctx)
+	require.NoError(t, err)
+
+	pool, err := pgxpool.New(ctx, connStr)
+	require.NoError(t, err)
+
+	_, err = pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS teams (
+			name TEXT PRIMARY KEY
+		);
+
+		CREATE TABLE IF NOT EXISTS repositories (
+			name TEXT PRIMARY KEY,
+			default_team TEXT REFERENCES teams(name) ON DELETE SET NULL,
+			reviewer_strategy TEXT NOT NULL DEFAULT '',
+			reviewer_count INT CHECK (reviewer_count >= 0),
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+
+		INSERT INTO teams (name) VALUES ('platform');
+	`)
+	require.NoError(t, err)
+
+	return pool
+}
+
+func TestRepositoryPostgresStorage_CreateAndUpdate(t *testing.T) {
+	pool := setupRepositoryTestDB(t)
+	storage := NewRepositoryPostgresStorage(pool)
+	ctx := context.Background()
+
+	tx, err := storage.RepositoryBeginTx(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback(ctx)
+
+	err = storage.CreateRepositoryTx(ctx, tx, models.Repository{Name: "api", DefaultTeam: "platform"})
+	require.NoError(t, err)
+
+	err = tx.Commit(ctx)
+	require.NoError(t, err)
+
+	repo, err := storage.GetRepositoryTx(ctx, nil, "api")
+	require.NoError(t, err)
+	assert.Equal(t, "platform", repo.DefaultTeam)
+	assert.Equal(t, "", repo.ReviewerStrategy)
+	assert.Nil(t, repo.ReviewerCount)
+	assert.False(t, repo.CreatedAt.IsZero())
+
+	err = storage.CreateRepositoryTx(ctx, nil, models.Repository{Name: "api"})
+	assert.ErrorIs(t, err, models.ErrRepositoryExists)
+
+	count := 3
+	repo.DefaultTeam = ""
+	repo.ReviewerStrategy = models.StrategyFirstN
+	repo.ReviewerCount = &count
+	err = storage.UpdateRepositoryTx(ctx, nil, *repo)
+	require.NoError(t, err)
+
+	updated, err := storage.GetRepositoryTx(ctx, nil, "api")
+	require.NoError(t, err)
+	assert.Equal(t, "", updated.DefaultTeam)
+	assert.Equal(t, models.StrategyFirstN, updated.ReviewerStrategy)
+	require.NotNil(t, updated.ReviewerCount)
+	assert.Equal(t, 3, *updated.ReviewerCount)
+
+	err = storage.UpdateRepositoryTx(ctx, nil, models.Repository{Name: "nonexistent"})
+	assert.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func TestRepositoryPostgresStorage_Get_NotFound(t *testing.T) {
+	pool := setupRepositoryTestDB(t)
+	storage := NewRepositoryPostgresStorage(pool)
+	ctx := context.Background()
+
+	repo, err := storage.GetRepositoryTx(ctx, nil, "nonexistent")
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	assert.Nil(t, repo)
+}
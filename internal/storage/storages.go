@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"subscription-budget/internal/models"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 )
@@ -11,8 +12,34 @@ type PullReqStorage interface {
 	CreatePRTx(ctx context.Context, tx pgx.Tx, pr models.PullRequest) error
 	GetPRByIDTx(ctx context.Context, tx pgx.Tx, prID string) (*models.PullRequest, error)
 	MergePRTx(ctx context.Context, tx pgx.Tx, prID string) error
-	UpdatePRReviewersTx(ctx context.Context, tx pgx.Tx, prID string, reviewers []string) error
-	GetPRsByReviewerTx(ctx context.Context, tx pgx.Tx, userID string) ([]models.PullRequestShort, error)
+	UpdatePRStatusTx(ctx context.Context, tx pgx.Tx, prID string, from string, to string) error
+	UpdatePRMetadataTx(ctx context.Context, tx pgx.Tx, pr models.PullRequest, version int) error
+	UpdatePRReviewersTx(ctx context.Context, tx pgx.Tx, prID string, reviewers []string, crossTeam []string) error
+	GetPRsByReviewerTx(ctx context.Context, tx pgx.Tx, userID string, repository string) ([]models.PullRequestShort, error)
+	GetOpenPRsByReviewerTx(ctx context.Context, tx pgx.Tx, userID string) ([]models.PullRequest, error)
+	GetOpenReviewLoadTx(ctx context.Context, tx pgx.Tx, userIDs []string) (map[string]int, error)
+	SubmitReviewTx(ctx context.Context, tx pgx.Tx, review models.SubmitReviewRequest) error
+	ResetReviewTx(ctx context.Context, tx pgx.Tx, prID string, reviewerID string) error
+	GetPRReviewsTx(ctx context.Context, tx pgx.Tx, prID string) ([]models.PullRequestReview, error)
+	GetPendingReviewsTx(ctx context.Context, tx pgx.Tx) ([]models.PullRequestReview, error)
+	MarkReviewOverdueTx(ctx context.Context, tx pgx.Tx, prID string, reviewerID string) error
+	CountOverdueReviewsTx(ctx context.Context, tx pgx.Tx) (int, error)
+	AddPRHistoryTx(ctx context.Context, tx pgx.Tx, entry models.PRHistoryEntry) error
+	GetPRHistoryTx(ctx context.Context, tx pgx.Tx, prID string) ([]models.PRHistoryEntry, error)
+	SetPRDependenciesTx(ctx context.Context, tx pgx.Tx, prID string, parents []string) error
+	GetPRStackTx(ctx context.Context, tx pgx.Tx, prID string) ([]models.PullRequest, error)
+	CreateCommentThreadTx(ctx context.Context, tx pgx.Tx, thread models.CommentThread) (int64, error)
+	AddCommentTx(ctx context.Context, tx pgx.Tx, comment models.Comment) (int64, error)
+	SetThreadResolvedTx(ctx context.Context, tx pgx.Tx, threadID int64, resolved bool, userID string) error
+	GetCommentThreadTx(ctx context.Context, tx pgx.Tx, threadID int64) (*models.CommentThread, error)
+	GetCommentThreadsTx(ctx context.Context, tx pgx.Tx, prID string) ([]models.CommentThread, error)
+	CountUnresolvedThreadsTx(ctx context.Context, tx pgx.Tx, prID string) (int, error)
+	GetUnderstaffedPRsTx(ctx context.Context, tx pgx.Tx, teamName string) ([]models.PullRequest, error)
+	GetStaleCandidatesTx(ctx context.Context, tx pgx.Tx, teamName string) ([]models.StalePR, error)
+	GetStaleCandidateTx(ctx context.Context, tx pgx.Tx, prID string) (*models.StalePR, error)
+	SetPRStaleTx(ctx context.Context, tx pgx.Tx, prID string, staleAt *time.Time) error
+	CreateAssignmentDecisionsTx(ctx context.Context, tx pgx.Tx, decisions []models.AssignmentDecision) error
+	GetAssignmentDecisionsTx(ctx context.Context, tx pgx.Tx, prID string) ([]models.AssignmentDecision, error)
 
 	PRBeginTx(ctx context.Context) (pgx.Tx, error)
 }
@@ -20,11 +47,46 @@ type PullReqStorage interface {
 type TeamStorage interface {
 	CreateTeamTx(ctx context.Context, tx pgx.Tx, team models.Team) error
 	GetTeamInfoTx(ctx context.Context, tx pgx.Tx, teamName string) (*models.Team, error)
+	UpdateTeamInfoTx(ctx context.Context, tx pgx.Tx, team models.Team) error
 	TeamBeginTx(ctx context.Context) (pgx.Tx, error)
+	GetTeamSettingsTx(ctx context.Context, tx pgx.Tx, teamName string) (*models.TeamSettings, error)
+	UpdateTeamSettingsTx(ctx context.Context, tx pgx.Tx, settings models.TeamSettings) error
+	SetRoundRobinCursorTx(ctx context.Context, tx pgx.Tx, teamName string, userID string) error
+	AddMemberTx(ctx context.Context, tx pgx.Tx, user models.User) error
+	AddMembershipTx(ctx context.Context, tx pgx.Tx, userID string, teamName string, primary bool) error
+	RemoveMembershipTx(ctx context.Context, tx pgx.Tx, userID string, teamName string) (bool, error)
+	SetPrimaryTeamTx(ctx context.Context, tx pgx.Tx, userID string, teamName string) error
+	GetMemberTeamsTx(ctx context.Context, tx pgx.Tx, userID string) ([]models.TeamMembership, error)
+	GetFallbackOfTx(ctx context.Context, tx pgx.Tx, teamName string) ([]string, error)
+	ClearDefaultTeamTx(ctx context.Context, tx pgx.Tx, teamName string) ([]string, error)
+	ArchiveTeamTx(ctx context.Context, tx pgx.Tx, teamName string) error
+	DeleteTeamTx(ctx context.Context, tx pgx.Tx, teamName string) (bool, error)
+	GetChildTeamsTx(ctx context.Context, tx pgx.Tx, teamName string) ([]string, error)
+	GetAncestorTeamsTx(ctx context.Context, tx pgx.Tx, teamName string) ([]string, error)
+	GetPoolMembersTx(ctx context.Context, tx pgx.Tx, teamName string) ([]models.User, error)
+	ReparentChildrenTx(ctx context.Context, tx pgx.Tx, teamName string) ([]string, error)
 }
 
 type UserStorage interface {
 	GetUserTx(ctx context.Context, tx pgx.Tx, userID string) (*models.User, error)
 	UpdateUserActiveTx(ctx context.Context, tx pgx.Tx, userID string, isActive bool) error
+	UpdateUserSkillsTx(ctx context.Context, tx pgx.Tx, userID string, skills []string) error
+	CreateAbsenceTx(ctx context.Context, tx pgx.Tx, absence models.UserAbsence) (*models.UserAbsence, error)
+	GetAbsencesTx(ctx context.Context, tx pgx.Tx, userID string) ([]models.UserAbsence, error)
+	DeleteAbsenceTx(ctx context.Context, tx pgx.Tx, absenceID int64) error
+	GetAbsentUsersTx(ctx context.Context, tx pgx.Tx, userIDs []string, at time.Time) (map[string]bool, error)
 	UserBeginTx(ctx context.Context) (pgx.Tx, error)
 }
+
+type RepositoryStorage interface {
+	CreateRepositoryTx(ctx context.Context, tx pgx.Tx, repo models.Repository) error
+	GetRepositoryTx(ctx context.Context, tx pgx.Tx, name string) (*models.Repository, error)
+	UpdateRepositoryTx(ctx context.Context, tx pgx.Tx, repo models.Repository) error
+	RepositoryBeginTx(ctx context.Context) (pgx.Tx, error)
+}
+
+type OwnershipStorage interface {
+	UpsertOwnershipFileTx(ctx context.Context, tx pgx.Tx, file models.OwnershipFile) error
+	GetOwnershipFileTx(ctx context.Context, tx pgx.Tx, scope string, scopeRef string) (*models.OwnershipFile, error)
+	OwnershipBeginTx(ctx context.Context) (pgx.Tx, error)
+}
@@ -3,12 +3,22 @@ package storage
 /*
 Основные функции:
 	1. Создание команды
-	2. Получение информации о команде
+	2. Получение информации о команде (метаданные и участники, команда может быть пустой)
+	   и изменение метаданных
 	3. Создать транзакцию
+	4. Получение и обновление настроек команды (стратегия и число ревьюеров,
+	   упорядоченный список запасных команд, политика merge и лид команды)
+	5. Участники (team_memberships): юзер может состоять в нескольких командах,
+	   одна из них может быть основной. Добавить, убрать, узнать команды юзера, сменить основную
+	6. Архивация и удаление команды: команда перестает быть запасной и командой
+	   по умолчанию репозиториев, при удалении удаляется и ее CODEOWNERS
+	7. Подкоманды (teams.parent_name): дочерние команды, цепочка родителей
+	   и пул участников команды со всеми подкомандами (для добора ревьюеров)
 
 Создание команды проихсодит атомарно.
-При создании происходит проверка через SQL запрос на то, существет
-ли человек, если да - обновляем его данные.
+При создании (и добавлении участника) существующий юзер вступает в команду, оставаясь
+в своих командах; его данные (имя, активность, навыки) не меняются - новые данные
+сохраняются только для нового юзера.
 
 Поиск юзеров за log из-за индексов
 
@@ -19,8 +29,10 @@ import (
 	"context"
 	"fmt"
 	"subscription-budget/internal/models"
+	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -60,85 +72,312 @@ func (s *TeamPostgresStorage) CreateTeamTx(ctx context.Context, tx pgx.Tx, team
 		return models.ErrTeamExists
 	}
 
+	insertQuery := `
+		INSERT INTO teams (name, description, chat_channel, review_policy, parent_name)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+	`
+	args := []any{team.TeamName, team.Description, team.ChatChannel, team.ReviewPolicy, team.ParentName}
 	if tx != nil {
-		_, err = tx.Exec(ctx, "INSERT INTO teams (name) VALUES ($1)", team.TeamName)
+		_, err = tx.Exec(ctx, insertQuery, args...)
 	} else {
-		_, err = s.pool.Exec(ctx, "INSERT INTO teams (name) VALUES ($1)", team.TeamName)
+		_, err = s.pool.Exec(ctx, insertQuery, args...)
 	}
 	if err != nil {
 		return fmt.Errorf("failed to create team: %w", err)
 	}
 
 	for _, member := range team.Members {
-		if err := s.createUserTx(ctx, tx, member); err != nil {
+		if err := s.AddMemberTx(ctx, tx, member); err != nil {
 			return fmt.Errorf("failed to create user %s: %w", member.UserID, err)
 		}
 	}
 
+	// Лид ссылается на участника, поэтому ставится после них
+	if team.LeadUserID != "" {
+		return s.UpdateTeamInfoTx(ctx, tx, team)
+	}
+
 	return nil
 }
 
-func (s *TeamPostgresStorage) createUserTx(ctx context.Context, tx pgx.Tx, user models.User) error {
+// UpdateTeamInfoTx сохраняет метаданные команды: описание, лида, чат, политику ревью
+// и родительскую команду
+func (s *TeamPostgresStorage) UpdateTeamInfoTx(ctx context.Context, tx pgx.Tx, team models.Team) error {
 	query := `
-		INSERT INTO users (user_id, username, team_name, is_active) 
+		UPDATE teams
+		SET description = $1,
+			lead_user_id = NULLIF($2, ''),
+			chat_channel = $3,
+			review_policy = $4,
+			parent_name = NULLIF($5, '')
+		WHERE name = $6
+	`
+	args := []any{team.Description, team.LeadUserID, team.ChatChannel, team.ReviewPolicy, team.ParentName, team.TeamName}
+
+	var result pgconn.CommandTag
+	var err error
+	if tx != nil {
+		result, err = tx.Exec(ctx, query, args...)
+	} else {
+		result, err = s.pool.Exec(ctx, query, args...)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update team: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+
+	return nil
+}
+
+// AddMemberTx добавляет юзера в команду user.TeamName. Новый юзер создается с переданными
+// данными, у существующего они не меняются.
+// Команда становится основной, если основной у юзера еще нет. Уже состоящий в ней - ErrAlreadyMember
+func (s *TeamPostgresStorage) AddMemberTx(ctx context.Context, tx pgx.Tx, user models.User) error {
+	query := `
+		INSERT INTO users (user_id, username, is_active, skills) 
 		VALUES ($1, $2, $3, $4)
-		ON CONFLICT (user_id) 
-		DO UPDATE SET username = EXCLUDED.username, team_name = EXCLUDED.team_name, is_active = EXCLUDED.is_active
+		ON CONFLICT (user_id) DO NOTHING
 	`
 
+	var err error
 	if tx != nil {
-		_, err := tx.Exec(ctx, query, user.UserID, user.Username, user.TeamName, user.IsActive)
-		if err != nil {
-			return fmt.Errorf("failed to create/update user: %w", err)
-		}
+		_, err = tx.Exec(ctx, query, user.UserID, user.Username, user.IsActive, nonNilSlice(user.Skills))
 	} else {
-		_, err := s.pool.Exec(ctx, query, user.UserID, user.Username, user.TeamName, user.IsActive)
-		if err != nil {
-			return fmt.Errorf("failed to create/update user: %w", err)
+		_, err = s.pool.Exec(ctx, query, user.UserID, user.Username, user.IsActive, nonNilSlice(user.Skills))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+
+	return s.AddMembershipTx(ctx, tx, user.UserID, user.TeamName, false)
+}
+
+// AddMembershipTx добавляет существующего юзера в команду. С primary команда становится
+// основной, без него - только если основной у юзера еще нет. Уже состоящий в ней - ErrAlreadyMember
+func (s *TeamPostgresStorage) AddMembershipTx(ctx context.Context, tx pgx.Tx, userID string, teamName string, primary bool) error {
+	query := `
+		INSERT INTO team_memberships (user_id, team_name, is_primary)
+		SELECT $1, $2, NOT EXISTS (
+			SELECT 1 FROM team_memberships WHERE user_id = $1 AND is_primary
+		)
+		ON CONFLICT (user_id, team_name) DO NOTHING
+	`
+
+	var result pgconn.CommandTag
+	var err error
+	if tx != nil {
+		result, err = tx.Exec(ctx, query, userID, teamName)
+	} else {
+		result, err = s.pool.Exec(ctx, query, userID, teamName)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to add membership: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return models.ErrAlreadyMember
+	}
+
+	if primary {
+		return s.SetPrimaryTeamTx(ctx, tx, userID, teamName)
+	}
+	return nil
+}
+
+// RemoveMembershipTx убирает юзера из команды (в других командах он остается).
+// Возвращает, была ли команда основной. Не состоящий в ней - ErrNotMember
+func (s *TeamPostgresStorage) RemoveMembershipTx(ctx context.Context, tx pgx.Tx, userID string, teamName string) (bool, error) {
+	query := `
+		DELETE FROM team_memberships
+		WHERE user_id = $1 AND team_name = $2
+		RETURNING is_primary
+	`
+
+	var row pgx.Row
+	if tx != nil {
+		row = tx.QueryRow(ctx, query, userID, teamName)
+	} else {
+		row = s.pool.QueryRow(ctx, query, userID, teamName)
+	}
+
+	var wasPrimary bool
+	if err := row.Scan(&wasPrimary); err != nil {
+		if err == pgx.ErrNoRows {
+			return false, models.ErrNotMember
 		}
+		return false, fmt.Errorf("failed to remove membership: %w", err)
+	}
+
+	return wasPrimary, nil
+}
+
+// SetPrimaryTeamTx делает команду основной для юзера; пустой teamName - основной команды нет.
+// Юзер должен состоять в команде, иначе ErrNotMember
+func (s *TeamPostgresStorage) SetPrimaryTeamTx(ctx context.Context, tx pgx.Tx, userID string, teamName string) error {
+	clearQuery := `
+		UPDATE team_memberships
+		SET is_primary = false
+		WHERE user_id = $1 AND is_primary
+	`
+	setQuery := `
+		UPDATE team_memberships
+		SET is_primary = true
+		WHERE user_id = $1 AND team_name = $2
+	`
+
+	var result pgconn.CommandTag
+	var err error
+	if tx != nil {
+		_, err = tx.Exec(ctx, clearQuery, userID)
+	} else {
+		_, err = s.pool.Exec(ctx, clearQuery, userID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to clear primary team: %w", err)
+	}
+
+	if teamName == "" {
+		return nil
 	}
+
+	if tx != nil {
+		result, err = tx.Exec(ctx, setQuery, userID, teamName)
+	} else {
+		result, err = s.pool.Exec(ctx, setQuery, userID, teamName)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set primary team: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return models.ErrNotMember
+	}
+
 	return nil
 }
 
-func (s *TeamPostgresStorage) GetTeamInfoTx(ctx context.Context, tx pgx.Tx, teamName string) (*models.Team, error) {
+// GetMemberTeamsTx - команды юзера, основная первой, остальные по времени вступления.
+// Пустой список, если юзер ни в одной команде; ErrNotFound, если юзера нет
+func (s *TeamPostgresStorage) GetMemberTeamsTx(ctx context.Context, tx pgx.Tx, userID string) ([]models.TeamMembership, error) {
 	query := `
+		SELECT u.user_id, m.team_name, COALESCE(m.is_primary, false), m.joined_at
+		FROM users u
+		LEFT JOIN team_memberships m ON m.user_id = u.user_id
+		WHERE u.user_id = $1
+		ORDER BY m.is_primary DESC, m.joined_at, m.team_name
+	`
+
+	var rows pgx.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.Query(ctx, query, userID)
+	} else {
+		rows, err = s.pool.Query(ctx, query, userID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user teams: %w", err)
+	}
+	defer rows.Close()
+
+	found := false
+	memberships := []models.TeamMembership{}
+	for rows.Next() {
+		found = true
+
+		var membership models.TeamMembership
+		var teamName *string
+		var joinedAt *time.Time
+		if err := rows.Scan(&membership.UserID, &teamName, &membership.IsPrimary, &joinedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan user team: %w", err)
+		}
+		if teamName == nil {
+			continue
+		}
+		membership.TeamName = *teamName
+		membership.JoinedAt = *joinedAt
+		memberships = append(memberships, membership)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating user teams: %w", err)
+	}
+
+	if !found {
+		return nil, models.ErrNotFound
+	}
+
+	return memberships, nil
+}
+
+func (s *TeamPostgresStorage) GetTeamInfoTx(ctx context.Context, tx pgx.Tx, teamName string) (*models.Team, error) {
+	teamQuery := `
+		SELECT name, COALESCE(parent_name, ''), description, COALESCE(lead_user_id, ''), chat_channel, review_policy, archived_at
+		FROM teams
+		WHERE name = $1
+	`
+
+	var row pgx.Row
+	if tx != nil {
+		row = tx.QueryRow(ctx, teamQuery, teamName)
+	} else {
+		row = s.pool.QueryRow(ctx, teamQuery, teamName)
+	}
+
+	var team models.Team
+	err := row.Scan(
+		&team.TeamName,
+		&team.ParentName,
+		&team.Description,
+		&team.LeadUserID,
+		&team.ChatChannel,
+		&team.ReviewPolicy,
+		&team.ArchivedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to query team: %w", err)
+	}
+
+	membersQuery := `
         SELECT 
-            t.name as team_name, 
             u.user_id, 
             u.username, 
-            u.team_name, 
-            u.is_active
-        FROM teams t
-        JOIN users u ON u.team_name = t.name
-        WHERE t.name = $1
+            m.team_name, 
+            u.is_active,
+            u.skills
+        FROM team_memberships m
+        JOIN users u ON u.user_id = m.user_id
+        WHERE m.team_name = $1
         ORDER BY u.user_id
     `
 
 	var rows pgx.Rows
-	var err error
-
 	if tx != nil {
-		rows, err = tx.Query(ctx, query, teamName)
+		rows, err = tx.Query(ctx, membersQuery, teamName)
 	} else {
-		rows, err = s.pool.Query(ctx, query, teamName)
+		rows, err = s.pool.Query(ctx, membersQuery, teamName)
 	}
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to query team: %w", err)
+		return nil, fmt.Errorf("failed to query team members: %w", err)
 	}
 	defer rows.Close()
 
-	var team models.Team
-	var members []models.User
+	members := []models.User{}
 
 	for rows.Next() {
 		var user models.User
 		err := rows.Scan(
-			&team.TeamName,
 			&user.UserID,
 			&user.Username,
 			&user.TeamName,
 			&user.IsActive,
+			&user.Skills,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan team member: %w", err)
@@ -150,10 +389,487 @@ func (s *TeamPostgresStorage) GetTeamInfoTx(ctx context.Context, tx pgx.Tx, team
 		return nil, fmt.Errorf("error iterating team members: %w", err)
 	}
 
-	if len(members) == 0 {
-		return nil, models.ErrNotFound
-	}
-
 	team.Members = members
 	return &team, nil
 }
+
+func (s *TeamPostgresStorage) GetTeamSettingsTx(ctx context.Context, tx pgx.Tx, teamName string) (*models.TeamSettings, error) {
+	query := `
+		SELECT
+			name,
+			reviewer_strategy,
+			reviewer_seed,
+			reviewer_count,
+			rr_cursor,
+			parent_pool,
+			max_open_reviews,
+			min_approvals,
+			block_on_changes_requested,
+			require_lead_approval,
+			COALESCE(lead_user_id, ''),
+			require_resolved_threads,
+			review_sla_hours,
+			sla_reassign,
+			stale_enabled,
+			stale_after_days,
+			stale_close_after_days
+		FROM teams
+		WHERE name = $1
+	`
+
+	var row pgx.Row
+	if tx != nil {
+		row = tx.QueryRow(ctx, query, teamName)
+	} else {
+		row = s.pool.QueryRow(ctx, query, teamName)
+	}
+
+	var settings models.TeamSettings
+	err := row.Scan(
+		&settings.TeamName,
+		&settings.ReviewerStrategy,
+		&settings.ReviewerSeed,
+		&settings.ReviewerCount,
+		&settings.RoundRobinCursor,
+		&settings.ParentPool,
+		&settings.MaxOpenReviews,
+		&settings.MinApprovals,
+		&settings.BlockOnChangesRequested,
+		&settings.RequireLeadApproval,
+		&settings.LeadUserID,
+		&settings.RequireResolvedThreads,
+		&settings.ReviewSLAHours,
+		&settings.SLAReassign,
+		&settings.StaleEnabled,
+		&settings.StaleAfterDays,
+		&settings.StaleCloseAfterDays,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get team settings: %w", err)
+	}
+
+	settings.FallbackTeams, err = s.getFallbackTeamsTx(ctx, tx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &settings, nil
+}
+
+func (s *TeamPostgresStorage) UpdateTeamSettingsTx(ctx context.Context, tx pgx.Tx, settings models.TeamSettings) error {
+	query := `
+		UPDATE teams
+		SET reviewer_strategy = $1,
+			reviewer_seed = $2,
+			reviewer_count = $3,
+			min_approvals = $4,
+			block_on_changes_requested = $5,
+			require_lead_approval = $6,
+			lead_user_id = NULLIF($7, ''),
+			require_resolved_threads = $8,
+			review_sla_hours = $9,
+			sla_reassign = $10,
+			stale_enabled = $11,
+			stale_after_days = $12,
+			stale_close_after_days = $13,
+			parent_pool = $14,
+			max_open_reviews = $15
+		WHERE name = $16
+	`
+	args := []any{
+		settings.ReviewerStrategy,
+		settings.ReviewerSeed,
+		settings.ReviewerCount,
+		settings.MinApprovals,
+		settings.BlockOnChangesRequested,
+		settings.RequireLeadApproval,
+		settings.LeadUserID,
+		settings.RequireResolvedThreads,
+		settings.ReviewSLAHours,
+		settings.SLAReassign,
+		settings.StaleEnabled,
+		settings.StaleAfterDays,
+		settings.StaleCloseAfterDays,
+		settings.ParentPool,
+		settings.MaxOpenReviews,
+		settings.TeamName,
+	}
+
+	var result pgconn.CommandTag
+	var err error
+	if tx != nil {
+		result, err = tx.Exec(ctx, query, args...)
+	} else {
+		result, err = s.pool.Exec(ctx, query, args...)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update team settings: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+
+	return s.replaceFallbackTeamsTx(ctx, tx, settings.TeamName, settings.FallbackTeams)
+}
+
+func (s *TeamPostgresStorage) SetRoundRobinCursorTx(ctx context.Context, tx pgx.Tx, teamName string, userID string) error {
+	query := `UPDATE teams SET rr_cursor = $1 WHERE name = $2`
+
+	var err error
+	if tx != nil {
+		_, err = tx.Exec(ctx, query, userID, teamName)
+	} else {
+		_, err = s.pool.Exec(ctx, query, userID, teamName)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update round-robin cursor: %w", err)
+	}
+
+	return nil
+}
+
+func (s *TeamPostgresStorage) getFallbackTeamsTx(ctx context.Context, tx pgx.Tx, teamName string) ([]string, error) {
+	query := `
+		SELECT fallback_team
+		FROM team_fallbacks
+		WHERE team_name = $1
+		ORDER BY position
+	`
+
+	var rows pgx.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.Query(ctx, query, teamName)
+	} else {
+		rows, err = s.pool.Query(ctx, query, teamName)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query fallback teams: %w", err)
+	}
+	defer rows.Close()
+
+	fallbacks := []string{}
+	for rows.Next() {
+		var fallback string
+		if err := rows.Scan(&fallback); err != nil {
+			return nil, fmt.Errorf("failed to scan fallback team: %w", err)
+		}
+		fallbacks = append(fallbacks, fallback)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating fallback teams: %w", err)
+	}
+
+	return fallbacks, nil
+}
+
+func (s *TeamPostgresStorage) replaceFallbackTeamsTx(ctx context.Context, tx pgx.Tx, teamName string, fallbacks []string) error {
+	deleteQuery := `DELETE FROM team_fallbacks WHERE team_name = $1`
+	insertQuery := `
+		INSERT INTO team_fallbacks (team_name, fallback_team, position)
+		VALUES ($1, $2, $3)
+	`
+
+	var err error
+	if tx != nil {
+		_, err = tx.Exec(ctx, deleteQuery, teamName)
+	} else {
+		_, err = s.pool.Exec(ctx, deleteQuery, teamName)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to clear fallback teams: %w", err)
+	}
+
+	for position, fallback := range fallbacks {
+		if tx != nil {
+			_, err = tx.Exec(ctx, insertQuery, teamName, fallback, position)
+		} else {
+			_, err = s.pool.Exec(ctx, insertQuery, teamName, fallback, position)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to add fallback team %s: %w", fallback, err)
+		}
+	}
+
+	return nil
+}
+
+// GetFallbackOfTx - команды, у которых teamName в списке запасных
+func (s *TeamPostgresStorage) GetFallbackOfTx(ctx context.Context, tx pgx.Tx, teamName string) ([]string, error) {
+	query := `
+		SELECT team_name
+		FROM team_fallbacks
+		WHERE fallback_team = $1
+		ORDER BY team_name
+	`
+
+	var rows pgx.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.Query(ctx, query, teamName)
+	} else {
+		rows, err = s.pool.Query(ctx, query, teamName)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query fallback dependents: %w", err)
+	}
+	defer rows.Close()
+
+	teams := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan fallback dependent: %w", err)
+		}
+		teams = append(teams, name)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating fallback dependents: %w", err)
+	}
+
+	return teams, nil
+}
+
+// ClearDefaultTeamTx снимает команду с репозиториев, где она команда по умолчанию.
+// Возвращает эти репозитории
+func (s *TeamPostgresStorage) ClearDefaultTeamTx(ctx context.Context, tx pgx.Tx, teamName string) ([]string, error) {
+	query := `
+		UPDATE repositories
+		SET default_team = NULL
+		WHERE default_team = $1
+		RETURNING name
+	`
+
+	var rows pgx.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.Query(ctx, query, teamName)
+	} else {
+		rows, err = s.pool.Query(ctx, query, teamName)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to clear default team: %w", err)
+	}
+	defer rows.Close()
+
+	repos := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan repository: %w", err)
+		}
+		repos = append(repos, name)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating repositories: %w", err)
+	}
+
+	return repos, nil
+}
+
+// ArchiveTeamTx архивирует команду и убирает ее из списков запасных (своего и чужих).
+// Уже архивная команда - ErrTeamArchived
+func (s *TeamPostgresStorage) ArchiveTeamTx(ctx context.Context, tx pgx.Tx, teamName string) error {
+	archiveQuery := `
+		UPDATE teams
+		SET archived_at = NOW()
+		WHERE name = $1 AND archived_at IS NULL
+	`
+	fallbacksQuery := `
+		DELETE FROM team_fallbacks
+		WHERE team_name = $1 OR fallback_team = $1
+	`
+
+	var result pgconn.CommandTag
+	var err error
+	if tx != nil {
+		result, err = tx.Exec(ctx, archiveQuery, teamName)
+	} else {
+		result, err = s.pool.Exec(ctx, archiveQuery, teamName)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to archive team: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return models.ErrTeamArchived
+	}
+
+	if tx != nil {
+		_, err = tx.Exec(ctx, fallbacksQuery, teamName)
+	} else {
+		_, err = s.pool.Exec(ctx, fallbacksQuery, teamName)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to remove team fallbacks: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteTeamTx удаляет команду вместе с ее CODEOWNERS; списки запасных чистятся каскадом.
+// Участников к этому моменту в команде быть не должно. Возвращает, был ли CODEOWNERS
+func (s *TeamPostgresStorage) DeleteTeamTx(ctx context.Context, tx pgx.Tx, teamName string) (bool, error) {
+	ownershipQuery := `
+		DELETE FROM ownership_files
+		WHERE scope = $1 AND scope_ref = $2
+	`
+	teamQuery := `DELETE FROM teams WHERE name = $1`
+
+	var result pgconn.CommandTag
+	var err error
+	if tx != nil {
+		result, err = tx.Exec(ctx, ownershipQuery, models.OwnershipScopeTeam, teamName)
+	} else {
+		result, err = s.pool.Exec(ctx, ownershipQuery, models.OwnershipScopeTeam, teamName)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to delete team codeowners: %w", err)
+	}
+	codeOwnersRemoved := result.RowsAffected() > 0
+
+	if tx != nil {
+		result, err = tx.Exec(ctx, teamQuery, teamName)
+	} else {
+		result, err = s.pool.Exec(ctx, teamQuery, teamName)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to delete team: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return false, models.ErrNotFound
+	}
+
+	return codeOwnersRemoved, nil
+}
+
+// GetChildTeamsTx - прямые подкоманды teamName по имени
+func (s *TeamPostgresStorage) GetChildTeamsTx(ctx context.Context, tx pgx.Tx, teamName string) ([]string, error) {
+	query := `
+		SELECT name
+		FROM teams
+		WHERE parent_name = $1
+		ORDER BY name
+	`
+
+	return s.queryTeamNamesTx(ctx, tx, query, teamName)
+}
+
+// GetAncestorTeamsTx - цепочка родителей teamName, начиная с ближайшего
+func (s *TeamPostgresStorage) GetAncestorTeamsTx(ctx context.Context, tx pgx.Tx, teamName string) ([]string, error) {
+	query := `
+		WITH RECURSIVE ancestors AS (
+			SELECT parent_name AS name, 1 AS depth, ARRAY[name] AS path
+			FROM teams
+			WHERE name = $1 AND parent_name IS NOT NULL
+			UNION ALL
+			SELECT t.parent_name, a.depth + 1, a.path || t.name
+			FROM ancestors a
+			JOIN teams t ON t.name = a.name
+			WHERE t.parent_name IS NOT NULL AND NOT t.parent_name = ANY(a.path)
+		)
+		SELECT name
+		FROM ancestors
+		ORDER BY depth
+	`
+
+	return s.queryTeamNamesTx(ctx, tx, query, teamName)
+}
+
+// GetPoolMembersTx - участники команды и всех ее подкоманд (кроме архивных) без повторов
+func (s *TeamPostgresStorage) GetPoolMembersTx(ctx context.Context, tx pgx.Tx, teamName string) ([]models.User, error) {
+	query := `
+		WITH RECURSIVE pool AS (
+			SELECT name, ARRAY[name] AS path
+			FROM teams
+			WHERE name = $1 AND archived_at IS NULL
+			UNION ALL
+			SELECT t.name, p.path || t.name
+			FROM pool p
+			JOIN teams t ON t.parent_name = p.name
+			WHERE t.archived_at IS NULL AND NOT t.name = ANY(p.path)
+		)
+		SELECT DISTINCT ON (u.user_id) u.user_id, u.username, m.team_name, u.is_active, u.skills
+		FROM team_memberships m
+		JOIN users u ON u.user_id = m.user_id
+		WHERE m.team_name IN (SELECT name FROM pool)
+		ORDER BY u.user_id, m.team_name
+	`
+
+	var rows pgx.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.Query(ctx, query, teamName)
+	} else {
+		rows, err = s.pool.Query(ctx, query, teamName)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query team pool: %w", err)
+	}
+	defer rows.Close()
+
+	members := []models.User{}
+	for rows.Next() {
+		var user models.User
+		if err := rows.Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive, &user.Skills); err != nil {
+			return nil, fmt.Errorf("failed to scan pool member: %w", err)
+		}
+		members = append(members, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating team pool: %w", err)
+	}
+
+	return members, nil
+}
+
+// ReparentChildrenTx переносит подкоманды teamName к ее родителю (или делает корневыми).
+// Возвращает перенесенные подкоманды
+func (s *TeamPostgresStorage) ReparentChildrenTx(ctx context.Context, tx pgx.Tx, teamName string) ([]string, error) {
+	query := `
+		UPDATE teams
+		SET parent_name = (SELECT parent_name FROM teams WHERE name = $1)
+		WHERE parent_name = $1
+		RETURNING name
+	`
+
+	return s.queryTeamNamesTx(ctx, tx, query, teamName)
+}
+
+func (s *TeamPostgresStorage) queryTeamNamesTx(ctx context.Context, tx pgx.Tx, query string, teamName string) ([]string, error) {
+	var rows pgx.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.Query(ctx, query, teamName)
+	} else {
+		rows, err = s.pool.Query(ctx, query, teamName)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query teams: %w", err)
+	}
+	defer rows.Close()
+
+	teams := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan team name: %w", err)
+		}
+		teams = append(teams, name)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating teams: %w", err)
+	}
+
+	return teams, nil
+}
@@ -6,8 +6,14 @@ package storage
 	1. Успешно ли создаются команды
 	2. Повторное создание команды с тем же именнем
 	3. Получение информацие по несуществующему имени
-	4. Проверка обновления данных
+	4. Создание команды добавляет юзера из другой команды, не меняя его данных
 	5. Проверка на праильно получение информации о пользователе
+	6. Чтение и обновление настроек команды
+	7. Участники: добавить, перевести в другую команду, убрать из команды
+	8. Команда без участников и метаданные команды
+	9. Архивация и удаление команды
+	10. Подкоманды: дочерние команды, родители, пул участников и перенос подкоманд
+	11. Юзер в нескольких командах: основная команда, выход из основной команды
 
 */
 import (
@@ -50,17 +56,66 @@ func setupTestDB(t *testing.T) *pgxpool.Pool {
 
 	_, err = pool.Exec(ctx, `
 		CREATE TABLE IF NOT EXISTS teams (
-			name TEXT PRIMARY KEY
+			name TEXT PRIMARY KEY,
+			reviewer_strategy TEXT NOT NULL DEFAULT 'least_loaded',
+			reviewer_seed BIGINT NOT NULL DEFAULT 0,
+			reviewer_count INT NOT NULL DEFAULT 2,
+			rr_cursor TEXT NOT NULL DEFAULT '',
+			min_approvals INT NOT NULL DEFAULT 0,
+			block_on_changes_requested BOOLEAN NOT NULL DEFAULT true,
+			require_lead_approval BOOLEAN NOT NULL DEFAULT false,
+			lead_user_id TEXT,
+			require_resolved_threads BOOLEAN NOT NULL DEFAULT false,
+			review_sla_hours INT NOT NULL DEFAULT 0,
+			sla_reassign BOOLEAN NOT NULL DEFAULT false,
+			stale_enabled BOOLEAN NOT NULL DEFAULT false,
+			stale_after_days INT NOT NULL DEFAULT 14,
+			stale_close_after_days INT NOT NULL DEFAULT 7,
+			description TEXT NOT NULL DEFAULT '',
+			chat_channel TEXT NOT NULL DEFAULT '',
+			review_policy TEXT NOT NULL DEFAULT '',
+			archived_at TIMESTAMPTZ,
+			parent_name TEXT REFERENCES teams(name) ON DELETE SET NULL,
+			parent_pool BOOLEAN NOT NULL DEFAULT true,
+			max_open_reviews INT NOT NULL DEFAULT 0
 		);
 		
 		CREATE TABLE IF NOT EXISTS users (
 			user_id TEXT PRIMARY KEY,
 			username TEXT NOT NULL,
+			is_active BOOLEAN NOT NULL DEFAULT true,
+			skills TEXT[] NOT NULL DEFAULT '{}'
+		);
+
+		CREATE TABLE IF NOT EXISTS team_memberships (
+			user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+			team_name TEXT NOT NULL REFERENCES teams(name) ON DELETE CASCADE,
+			is_primary BOOLEAN NOT NULL DEFAULT false,
+			joined_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (user_id, team_name)
+		);
+
+		CREATE TABLE IF NOT EXISTS team_fallbacks (
 			team_name TEXT NOT NULL REFERENCES teams(name) ON DELETE CASCADE,
-			is_active BOOLEAN NOT NULL DEFAULT true
+			fallback_team TEXT NOT NULL REFERENCES teams(name) ON DELETE CASCADE,
+			position INT NOT NULL,
+			PRIMARY KEY (team_name, fallback_team)
 		);
 
-		CREATE INDEX IF NOT EXISTS idx_users_team ON users(team_name);
+		CREATE TABLE IF NOT EXISTS repositories (
+			name TEXT PRIMARY KEY,
+			default_team TEXT REFERENCES teams(name) ON DELETE SET NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS ownership_files (
+			scope TEXT NOT NULL,
+			scope_ref TEXT NOT NULL,
+			content TEXT NOT NULL,
+			PRIMARY KEY (scope, scope_ref)
+		);
+
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_team_memberships_primary ON team_memberships(user_id) WHERE is_primary;
+		CREATE INDEX IF NOT EXISTS idx_team_memberships_team ON team_memberships(team_name);
 		CREATE INDEX IF NOT EXISTS idx_users_active ON users(is_active);
 	`)
 	require.NoError(t, err)
@@ -150,7 +205,7 @@ func TestTeamPostgresStorage_GetTeamInfo_NotFound(t *testing.T) {
 	assert.Nil(t, team)
 }
 
-func TestTeamPostgresStorage_CreateTeam_UpdatesUserTeam(t *testing.T) {
+func TestTeamPostgresStorage_CreateTeam_UserInSeveralTeams(t *testing.T) {
 	pool := setupTestDB(t)
 	storage := NewTeamPostgresStorage(pool)
 	ctx := context.Background()
@@ -185,14 +240,118 @@ func TestTeamPostgresStorage_CreateTeam_UpdatesUserTeam(t *testing.T) {
 	err = tx.Commit(ctx)
 	require.NoError(t, err)
 
-	tx, err = storage.TeamBeginTx(ctx)
+	team, err := storage.GetTeamInfoTx(ctx, nil, "team2")
 	require.NoError(t, err)
-	defer tx.Rollback(ctx)
+	require.Len(t, team.Members, 1)
+	assert.Equal(t, "team2", team.Members[0].TeamName)
+	assert.True(t, team.Members[0].IsActive)
 
-	team, err := storage.GetTeamInfoTx(ctx, tx, "team2")
+	memberships, err := storage.GetMemberTeamsTx(ctx, nil, "u1")
 	require.NoError(t, err)
-	assert.Equal(t, "team2", team.Members[0].TeamName)
-	assert.False(t, team.Members[0].IsActive)
+	require.Len(t, memberships, 2)
+	assert.Equal(t, "team1", memberships[0].TeamName)
+	assert.True(t, memberships[0].IsPrimary)
+	assert.Equal(t, "team2", memberships[1].TeamName)
+	assert.False(t, memberships[1].IsPrimary)
+}
+
+func TestTeamPostgresStorage_Members(t *testing.T) {
+	pool := setupTestDB(t)
+	storage := NewTeamPostgresStorage(pool)
+	ctx := context.Background()
+
+	for _, team := range []models.Team{
+		{TeamName: "backend", Members: []models.User{{UserID: "u1", Username: "Alice", TeamName: "backend", IsActive: true}}},
+		{TeamName: "frontend", Members: []models.User{{UserID: "u2", Username: "Bob", TeamName: "frontend", IsActive: true}}},
+	} {
+		require.NoError(t, storage.CreateTeamTx(ctx, nil, team))
+	}
+
+	err := storage.AddMemberTx(ctx, nil, models.User{UserID: "u3", Username: "Carol", TeamName: "backend", IsActive: true})
+	require.NoError(t, err)
+
+	err = storage.AddMemberTx(ctx, nil, models.User{UserID: "u3", Username: "Carol", TeamName: "backend", IsActive: true})
+	assert.ErrorIs(t, err, models.ErrAlreadyMember)
+
+	err = storage.AddMemberTx(ctx, nil, models.User{UserID: "u2", Username: "Bob B.", TeamName: "backend", IsActive: false})
+	require.NoError(t, err)
+
+	team, err := storage.GetTeamInfoTx(ctx, nil, "backend")
+	require.NoError(t, err)
+	require.Len(t, team.Members, 3)
+	assert.Equal(t, "Bob", team.Members[1].Username)
+	assert.True(t, team.Members[1].IsActive)
+
+	wasPrimary, err := storage.RemoveMembershipTx(ctx, nil, "u3", "backend")
+	require.NoError(t, err)
+	assert.True(t, wasPrimary)
+	_, err = storage.RemoveMembershipTx(ctx, nil, "u3", "backend")
+	assert.ErrorIs(t, err, models.ErrNotMember)
+
+	memberships, err := storage.GetMemberTeamsTx(ctx, nil, "u3")
+	require.NoError(t, err)
+	assert.Empty(t, memberships)
+
+	err = storage.AddMemberTx(ctx, nil, models.User{UserID: "u3", Username: "Carol B.", TeamName: "backend", IsActive: false})
+	require.NoError(t, err)
+
+	team, err = storage.GetTeamInfoTx(ctx, nil, "backend")
+	require.NoError(t, err)
+	require.Len(t, team.Members, 3)
+	assert.Equal(t, "Carol", team.Members[2].Username)
+	assert.True(t, team.Members[2].IsActive)
+
+	_, err = storage.GetMemberTeamsTx(ctx, nil, "missing")
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	err = storage.AddMembershipTx(ctx, nil, "u3", "missing", false)
+	assert.Error(t, err)
+}
+
+func TestTeamPostgresStorage_PrimaryTeam(t *testing.T) {
+	pool := setupTestDB(t)
+	storage := NewTeamPostgresStorage(pool)
+	ctx := context.Background()
+
+	for _, name := range []string{"backend", "frontend", "platform"} {
+		require.NoError(t, storage.CreateTeamTx(ctx, nil, models.Team{TeamName: name}))
+	}
+	require.NoError(t, storage.AddMemberTx(ctx, nil, models.User{UserID: "u1", Username: "Alice", TeamName: "backend", IsActive: true}))
+	require.NoError(t, storage.AddMembershipTx(ctx, nil, "u1", "frontend", false))
+	require.NoError(t, storage.AddMembershipTx(ctx, nil, "u1", "platform", true))
+	assert.ErrorIs(t, storage.AddMembershipTx(ctx, nil, "u1", "frontend", false), models.ErrAlreadyMember)
+
+	memberships, err := storage.GetMemberTeamsTx(ctx, nil, "u1")
+	require.NoError(t, err)
+	require.Len(t, memberships, 3)
+	assert.Equal(t, "platform", memberships[0].TeamName)
+	assert.True(t, memberships[0].IsPrimary)
+	assert.False(t, memberships[1].IsPrimary)
+	assert.False(t, memberships[2].IsPrimary)
+
+	require.NoError(t, storage.SetPrimaryTeamTx(ctx, nil, "u1", "frontend"))
+	assert.ErrorIs(t, storage.SetPrimaryTeamTx(ctx, nil, "u1", "missing"), models.ErrNotMember)
+
+	memberships, err = storage.GetMemberTeamsTx(ctx, nil, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, "frontend", memberships[0].TeamName)
+	assert.True(t, memberships[0].IsPrimary)
+
+	wasPrimary, err := storage.RemoveMembershipTx(ctx, nil, "u1", "backend")
+	require.NoError(t, err)
+	assert.False(t, wasPrimary)
+
+	require.NoError(t, storage.SetPrimaryTeamTx(ctx, nil, "u1", ""))
+	memberships, err = storage.GetMemberTeamsTx(ctx, nil, "u1")
+	require.NoError(t, err)
+	require.Len(t, memberships, 2)
+	for _, membership := range memberships {
+		assert.False(t, membership.IsPrimary)
+	}
+
+	team, err := storage.GetTeamInfoTx(ctx, nil, "platform")
+	require.NoError(t, err)
+	require.Len(t, team.Members, 1)
+	assert.Equal(t, "platform", team.Members[0].TeamName)
 }
 
 func TestTeamPostgresStorage_GetTeamInfo_Success(t *testing.T) {
@@ -279,3 +438,255 @@ func TestTeamPostgresStorage_Transaction_Rollback(t *testing.T) {
 	_, err = storage.GetTeamInfoTx(ctx, tx, "rollback_test")
 	assert.ErrorIs(t, err, models.ErrNotFound)
 }
+
+func TestTeamPostgresStorage_TeamSettings(t *testing.T) {
+	pool := setupTestDB(t)
+	storage := NewTeamPostgresStorage(pool)
+	ctx := context.Background()
+
+	team := models.Team{
+		TeamName: "platform",
+		Members: []models.User{
+			{UserID: "u1", Username: "Alice", TeamName: "platform", IsActive: true},
+		},
+	}
+
+	fallback := models.Team{
+		TeamName: "infra",
+		Members: []models.User{
+			{UserID: "u2", Username: "Bob", TeamName: "infra", IsActive: true},
+		},
+	}
+
+	tx, err := storage.TeamBeginTx(ctx)
+	require.NoError(t, err)
+	err = storage.CreateTeamTx(ctx, tx, team)
+	require.NoError(t, err)
+	err = storage.CreateTeamTx(ctx, tx, fallback)
+	require.NoError(t, err)
+	err = tx.Commit(ctx)
+	require.NoError(t, err)
+
+	settings, err := storage.GetTeamSettingsTx(ctx, nil, "platform")
+	require.NoError(t, err)
+	assert.Equal(t, models.StrategyLeastLoaded, settings.ReviewerStrategy)
+	assert.Empty(t, settings.FallbackTeams)
+	assert.Equal(t, 2, settings.ReviewerCount)
+	assert.Equal(t, 0, settings.MaxOpenReviews)
+	assert.Equal(t, "", settings.RoundRobinCursor)
+	assert.Equal(t, 0, settings.MinApprovals)
+	assert.True(t, settings.BlockOnChangesRequested)
+	assert.Equal(t, "", settings.LeadUserID)
+	assert.False(t, settings.RequireResolvedThreads)
+	assert.Equal(t, 0, settings.ReviewSLAHours)
+	assert.False(t, settings.SLAReassign)
+	assert.False(t, settings.StaleEnabled)
+	assert.Equal(t, 14, settings.StaleAfterDays)
+	assert.Equal(t, 7, settings.StaleCloseAfterDays)
+
+	settings.ReviewerStrategy = models.StrategyRoundRobin
+	settings.ReviewerSeed = 42
+	settings.ReviewerCount = 3
+	settings.MaxOpenReviews = 5
+	settings.FallbackTeams = []string{"infra"}
+	settings.MinApprovals = 2
+	settings.BlockOnChangesRequested = false
+	settings.RequireLeadApproval = true
+	settings.LeadUserID = "u1"
+	settings.RequireResolvedThreads = true
+	settings.ReviewSLAHours = 8
+	settings.SLAReassign = true
+	settings.StaleEnabled = true
+	settings.StaleAfterDays = 30
+	settings.StaleCloseAfterDays = 0
+	err = storage.UpdateTeamSettingsTx(ctx, nil, *settings)
+	require.NoError(t, err)
+	err = storage.SetRoundRobinCursorTx(ctx, nil, "platform", "u1")
+	require.NoError(t, err)
+
+	updated, err := storage.GetTeamSettingsTx(ctx, nil, "platform")
+	require.NoError(t, err)
+	assert.Equal(t, models.StrategyRoundRobin, updated.ReviewerStrategy)
+	assert.Equal(t, int64(42), updated.ReviewerSeed)
+	assert.Equal(t, 3, updated.ReviewerCount)
+	assert.Equal(t, 5, updated.MaxOpenReviews)
+	assert.Equal(t, []string{"infra"}, updated.FallbackTeams)
+	assert.Equal(t, "u1", updated.RoundRobinCursor)
+	assert.Equal(t, 2, updated.MinApprovals)
+	assert.False(t, updated.BlockOnChangesRequested)
+	assert.True(t, updated.RequireLeadApproval)
+	assert.Equal(t, "u1", updated.LeadUserID)
+	assert.True(t, updated.RequireResolvedThreads)
+	assert.Equal(t, 8, updated.ReviewSLAHours)
+	assert.True(t, updated.SLAReassign)
+	assert.True(t, updated.StaleEnabled)
+	assert.Equal(t, 30, updated.StaleAfterDays)
+	assert.Equal(t, 0, updated.StaleCloseAfterDays)
+
+	_, err = storage.GetTeamSettingsTx(ctx, nil, "nonexistent")
+	assert.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func TestTeamPostgresStorage_EmptyTeamAndMetadata(t *testing.T) {
+	pool := setupTestDB(t)
+	storage := NewTeamPostgresStorage(pool)
+	ctx := context.Background()
+
+	err := storage.CreateTeamTx(ctx, nil, models.Team{
+		TeamName:    "platform",
+		Description: "Core services",
+		ChatChannel: "#platform",
+	})
+	require.NoError(t, err)
+
+	team, err := storage.GetTeamInfoTx(ctx, nil, "platform")
+	require.NoError(t, err)
+	assert.Equal(t, "Core services", team.Description)
+	assert.Equal(t, "#platform", team.ChatChannel)
+	assert.Equal(t, "", team.LeadUserID)
+	assert.NotNil(t, team.Members)
+	assert.Empty(t, team.Members)
+
+	err = storage.AddMemberTx(ctx, nil, models.User{UserID: "u1", Username: "Alice", TeamName: "platform", IsActive: true})
+	require.NoError(t, err)
+
+	team.LeadUserID = "u1"
+	team.ReviewPolicy = "Two approvals for schema changes"
+	err = storage.UpdateTeamInfoTx(ctx, nil, *team)
+	require.NoError(t, err)
+
+	updated, err := storage.GetTeamInfoTx(ctx, nil, "platform")
+	require.NoError(t, err)
+	assert.Equal(t, "u1", updated.LeadUserID)
+	assert.Equal(t, "Two approvals for schema changes", updated.ReviewPolicy)
+	require.Len(t, updated.Members, 1)
+
+	settings, err := storage.GetTeamSettingsTx(ctx, nil, "platform")
+	require.NoError(t, err)
+	assert.Equal(t, "u1", settings.LeadUserID)
+
+	err = storage.UpdateTeamInfoTx(ctx, nil, models.Team{TeamName: "nonexistent"})
+	assert.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func TestTeamPostgresStorage_ArchiveAndDelete(t *testing.T) {
+	pool := setupTestDB(t)
+	storage := NewTeamPostgresStorage(pool)
+	ctx := context.Background()
+
+	for _, name := range []string{"legacy", "platform", "backend"} {
+		require.NoError(t, storage.CreateTeamTx(ctx, nil, models.Team{TeamName: name}))
+	}
+	require.NoError(t, storage.AddMemberTx(ctx, nil, models.User{UserID: "u1", Username: "Alice", TeamName: "legacy", IsActive: true}))
+
+	settings, err := storage.GetTeamSettingsTx(ctx, nil, "platform")
+	require.NoError(t, err)
+	settings.FallbackTeams = []string{"legacy"}
+	require.NoError(t, storage.UpdateTeamSettingsTx(ctx, nil, *settings))
+
+	_, err = pool.Exec(ctx, `
+		INSERT INTO repositories (name, default_team) VALUES ('billing', 'legacy');
+		INSERT INTO ownership_files (scope, scope_ref, content) VALUES ('team', 'legacy', '* @u1');
+	`)
+	require.NoError(t, err)
+
+	dependents, err := storage.GetFallbackOfTx(ctx, nil, "legacy")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"platform"}, dependents)
+
+	repos, err := storage.ClearDefaultTeamTx(ctx, nil, "legacy")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"billing"}, repos)
+
+	require.NoError(t, storage.ArchiveTeamTx(ctx, nil, "legacy"))
+	assert.ErrorIs(t, storage.ArchiveTeamTx(ctx, nil, "legacy"), models.ErrTeamArchived)
+
+	archived, err := storage.GetTeamInfoTx(ctx, nil, "legacy")
+	require.NoError(t, err)
+	assert.NotNil(t, archived.ArchivedAt)
+	assert.Len(t, archived.Members, 1)
+
+	settings, err = storage.GetTeamSettingsTx(ctx, nil, "platform")
+	require.NoError(t, err)
+	assert.Empty(t, settings.FallbackTeams)
+
+	require.NoError(t, storage.AddMembershipTx(ctx, nil, "u1", "backend", true))
+
+	removed, err := storage.DeleteTeamTx(ctx, nil, "legacy")
+	require.NoError(t, err)
+	assert.True(t, removed)
+
+	_, err = storage.GetTeamInfoTx(ctx, nil, "legacy")
+	assert.ErrorIs(t, err, models.ErrNotFound)
+
+	memberships, err := storage.GetMemberTeamsTx(ctx, nil, "u1")
+	require.NoError(t, err)
+	require.Len(t, memberships, 1)
+	assert.Equal(t, "backend", memberships[0].TeamName)
+	assert.True(t, memberships[0].IsPrimary)
+
+	_, err = storage.DeleteTeamTx(ctx, nil, "legacy")
+	assert.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func TestTeamPostgresStorage_SubTeams(t *testing.T) {
+	pool := setupTestDB(t)
+	storage := NewTeamPostgresStorage(pool)
+	ctx := context.Background()
+
+	require.NoError(t, storage.CreateTeamTx(ctx, nil, models.Team{TeamName: "engineering"}))
+	require.NoError(t, storage.CreateTeamTx(ctx, nil, models.Team{TeamName: "platform", ParentName: "engineering"}))
+	for _, squad := range []string{"squad-a", "squad-b"} {
+		require.NoError(t, storage.CreateTeamTx(ctx, nil, models.Team{
+			TeamName:   squad,
+			ParentName: "platform",
+			Members: []models.User{
+				{UserID: squad + "-1", Username: squad + " one", TeamName: squad, IsActive: true},
+			},
+		}))
+	}
+	require.NoError(t, storage.AddMemberTx(ctx, nil, models.User{UserID: "p1", Username: "Platform lead", TeamName: "platform", IsActive: true}))
+
+	team, err := storage.GetTeamInfoTx(ctx, nil, "squad-a")
+	require.NoError(t, err)
+	assert.Equal(t, "platform", team.ParentName)
+
+	children, err := storage.GetChildTeamsTx(ctx, nil, "platform")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"squad-a", "squad-b"}, children)
+
+	ancestors, err := storage.GetAncestorTeamsTx(ctx, nil, "squad-a")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"platform", "engineering"}, ancestors)
+
+	members, err := storage.GetPoolMembersTx(ctx, nil, "platform")
+	require.NoError(t, err)
+	ids := make([]string, len(members))
+	for i, member := range members {
+		ids[i] = member.UserID
+	}
+	assert.Equal(t, []string{"p1", "squad-a-1", "squad-b-1"}, ids)
+
+	settings, err := storage.GetTeamSettingsTx(ctx, nil, "squad-a")
+	require.NoError(t, err)
+	assert.True(t, settings.ParentPool)
+	settings.ParentPool = false
+	require.NoError(t, storage.UpdateTeamSettingsTx(ctx, nil, *settings))
+	settings, err = storage.GetTeamSettingsTx(ctx, nil, "squad-a")
+	require.NoError(t, err)
+	assert.False(t, settings.ParentPool)
+
+	moved, err := storage.ReparentChildrenTx(ctx, nil, "platform")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"squad-a", "squad-b"}, moved)
+
+	ancestors, err = storage.GetAncestorTeamsTx(ctx, nil, "squad-b")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"engineering"}, ancestors)
+
+	team.ParentName = ""
+	require.NoError(t, storage.UpdateTeamInfoTx(ctx, nil, *team))
+	ancestors, err = storage.GetAncestorTeamsTx(ctx, nil, "squad-a")
+	require.NoError(t, err)
+	assert.Empty(t, ancestors)
+}
@@ -2,9 +2,13 @@ package storage
 
 /*
 Основные фукнции:
-	1. Получение данных о юзере по индексу
+	1. Получение данных о юзере по индексу (с его командами: основная или первая
+	   по времени вступления - team_name, все - teams)
 	2. Обновление активности юзера
 	3. Создать транзакцию
+	4. Обновление навыков юзера (skills)
+	5. Отсутствия (отпуск и т.п.): добавить, получить, удалить,
+	   найти тех, кто отсутствует в заданный момент
 
 Фича - если Tx - nil, то используем просто pool
 */
@@ -13,6 +17,7 @@ import (
 	"context"
 	"fmt"
 	"subscription-budget/internal/models"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -40,9 +45,24 @@ func (s *UserPostgresStorage) UserBeginTx(ctx context.Context) (pgx.Tx, error) {
 
 func (s *UserPostgresStorage) GetUserTx(ctx context.Context, tx pgx.Tx, userID string) (*models.User, error) {
 	query := `
-		SELECT user_id, username, team_name, is_active
-		FROM users 
-		WHERE user_id = $1
+		SELECT
+			u.user_id,
+			u.username,
+			COALESCE((
+				SELECT m.team_name FROM team_memberships m
+				WHERE m.user_id = u.user_id
+				ORDER BY m.is_primary DESC, m.joined_at, m.team_name
+				LIMIT 1
+			), ''),
+			u.is_active,
+			u.skills,
+			ARRAY(
+				SELECT m.team_name FROM team_memberships m
+				WHERE m.user_id = u.user_id
+				ORDER BY m.is_primary DESC, m.joined_at, m.team_name
+			)
+		FROM users u
+		WHERE u.user_id = $1
 	`
 
 	var user models.User
@@ -58,6 +78,8 @@ func (s *UserPostgresStorage) GetUserTx(ctx context.Context, tx pgx.Tx, userID s
 		&user.Username,
 		&user.TeamName,
 		&user.IsActive,
+		&user.Skills,
+		&user.Teams,
 	)
 
 	if err != nil {
@@ -96,3 +118,162 @@ func (s *UserPostgresStorage) UpdateUserActiveTx(ctx context.Context, tx pgx.Tx,
 
 	return nil
 }
+
+func (s *UserPostgresStorage) UpdateUserSkillsTx(ctx context.Context, tx pgx.Tx, userID string, skills []string) error {
+	query := `
+		UPDATE users 
+		SET skills = $1
+		WHERE user_id = $2
+	`
+
+	var result pgconn.CommandTag
+	var err error
+
+	if tx != nil {
+		result, err = tx.Exec(ctx, query, nonNilSlice(skills), userID)
+	} else {
+		result, err = s.pool.Exec(ctx, query, nonNilSlice(skills), userID)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to update user skills: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *UserPostgresStorage) CreateAbsenceTx(ctx context.Context, tx pgx.Tx, absence models.UserAbsence) (*models.UserAbsence, error) {
+	query := `
+		INSERT INTO user_absences (user_id, starts_at, ends_at, reason)
+		VALUES ($1, $2, $3, $4)
+		RETURNING absence_id, user_id, starts_at, ends_at, reason, created_at
+	`
+
+	var row pgx.Row
+	if tx != nil {
+		row = tx.QueryRow(ctx, query, absence.UserID, absence.StartsAt, absence.EndsAt, absence.Reason)
+	} else {
+		row = s.pool.QueryRow(ctx, query, absence.UserID, absence.StartsAt, absence.EndsAt, absence.Reason)
+	}
+
+	var created models.UserAbsence
+	err := row.Scan(
+		&created.AbsenceID,
+		&created.UserID,
+		&created.StartsAt,
+		&created.EndsAt,
+		&created.Reason,
+		&created.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create absence: %w", err)
+	}
+
+	return &created, nil
+}
+
+func (s *UserPostgresStorage) GetAbsencesTx(ctx context.Context, tx pgx.Tx, userID string) ([]models.UserAbsence, error) {
+	query := `
+		SELECT absence_id, user_id, starts_at, ends_at, reason, created_at
+		FROM user_absences
+		WHERE user_id = $1
+		ORDER BY starts_at
+	`
+
+	var rows pgx.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.Query(ctx, query, userID)
+	} else {
+		rows, err = s.pool.Query(ctx, query, userID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query absences: %w", err)
+	}
+	defer rows.Close()
+
+	absences := []models.UserAbsence{}
+	for rows.Next() {
+		var absence models.UserAbsence
+		err := rows.Scan(
+			&absence.AbsenceID,
+			&absence.UserID,
+			&absence.StartsAt,
+			&absence.EndsAt,
+			&absence.Reason,
+			&absence.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan absence: %w", err)
+		}
+		absences = append(absences, absence)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating absences: %w", err)
+	}
+
+	return absences, nil
+}
+
+func (s *UserPostgresStorage) DeleteAbsenceTx(ctx context.Context, tx pgx.Tx, absenceID int64) error {
+	query := `DELETE FROM user_absences WHERE absence_id = $1`
+
+	var result pgconn.CommandTag
+	var err error
+
+	if tx != nil {
+		result, err = tx.Exec(ctx, query, absenceID)
+	} else {
+		result, err = s.pool.Exec(ctx, query, absenceID)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to delete absence: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *UserPostgresStorage) GetAbsentUsersTx(ctx context.Context, tx pgx.Tx, userIDs []string, at time.Time) (map[string]bool, error) {
+	query := `
+		SELECT DISTINCT user_id
+		FROM user_absences
+		WHERE user_id = ANY($1) AND starts_at <= $2 AND ends_at > $2
+	`
+
+	var rows pgx.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.Query(ctx, query, userIDs, at)
+	} else {
+		rows, err = s.pool.Query(ctx, query, userIDs, at)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query absent users: %w", err)
+	}
+	defer rows.Close()
+
+	absent := make(map[string]bool)
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("failed to scan absent user: %w", err)
+		}
+		absent[userID] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating absent users: %w", err)
+	}
+
+	return absent, nil
+}
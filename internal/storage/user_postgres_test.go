@@ -73,14 +73,38 @@ func createTestTables(ctx context.Context, pool *pgxpool.Pool) error {
 		CREATE TABLE IF NOT EXISTS users (
 			user_id VARCHAR(50) PRIMARY KEY,
 			username VARCHAR(100) NOT NULL,
+			is_active BOOLEAN NOT NULL DEFAULT true,
+			skills TEXT[] NOT NULL DEFAULT '{}'
+		);
+
+		CREATE TABLE IF NOT EXISTS team_memberships (
+			user_id VARCHAR(50) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
 			team_name VARCHAR(100) NOT NULL,
-			is_active BOOLEAN NOT NULL DEFAULT true
+			is_primary BOOLEAN NOT NULL DEFAULT false,
+			joined_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (user_id, team_name)
+		);
+
+		CREATE TABLE IF NOT EXISTS user_absences (
+			absence_id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+			user_id VARCHAR(50) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+			starts_at TIMESTAMPTZ NOT NULL,
+			ends_at TIMESTAMPTZ NOT NULL,
+			reason TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			CHECK (ends_at > starts_at)
 		);
 
-		INSERT INTO users (user_id, username, team_name, is_active) VALUES
-			('user1', 'john_doe', 'Team Alpha', true),
-			('user2', 'jane_smith', 'Team Beta', false),
-			('user3', 'bob_wilson', 'Team Gamma', true);
+		INSERT INTO users (user_id, username, is_active) VALUES
+			('user1', 'john_doe', true),
+			('user2', 'jane_smith', false),
+			('user3', 'bob_wilson', true);
+
+		INSERT INTO team_memberships (user_id, team_name, is_primary) VALUES
+			('user1', 'Team Alpha', true),
+			('user2', 'Team Beta', true),
+			('user3', 'Team Gamma', false),
+			('user3', 'Team Alpha', true);
 	`
 
 	_, err := pool.Exec(ctx, query)
@@ -146,6 +170,35 @@ func TestUserPostgresStorage_UpdateUserActive(t *testing.T) {
 	})
 }
 
+func TestUserPostgresStorage_UpdateUserSkills(t *testing.T) {
+	pool := setupTestDatabase(t)
+	storage := NewUserPostgresStorage(pool)
+	ctx := context.Background()
+
+	t.Run("successfully update user skills", func(t *testing.T) {
+		err := storage.UpdateUserSkillsTx(ctx, nil, "user3", []string{"postgres", "security"})
+		require.NoError(t, err)
+
+		user, err := storage.GetUserTx(ctx, nil, "user3")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"postgres", "security"}, user.Skills)
+		assert.Equal(t, "Team Alpha", user.TeamName)
+		assert.Equal(t, []string{"Team Alpha", "Team Gamma"}, user.Teams)
+
+		err = storage.UpdateUserSkillsTx(ctx, nil, "user3", nil)
+		require.NoError(t, err)
+
+		user, err = storage.GetUserTx(ctx, nil, "user3")
+		require.NoError(t, err)
+		assert.Empty(t, user.Skills)
+	})
+
+	t.Run("update skills of non-existent user", func(t *testing.T) {
+		err := storage.UpdateUserSkillsTx(ctx, nil, "nonexistent", []string{"frontend"})
+		assert.Equal(t, models.ErrNotFound, err)
+	})
+}
+
 func TestNewUserPostgresStorage(t *testing.T) {
 	pool := &pgxpool.Pool{}
 	storage := NewUserPostgresStorage(pool)
@@ -153,3 +206,47 @@ func TestNewUserPostgresStorage(t *testing.T) {
 	assert.NotNil(t, storage)
 	assert.Equal(t, pool, storage.pool)
 }
+
+func TestUserPostgresStorage_Absences(t *testing.T) {
+	pool := setupTestDatabase(t)
+	storage := NewUserPostgresStorage(pool)
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+
+	current, err := storage.CreateAbsenceTx(ctx, nil, models.UserAbsence{
+		UserID:   "user1",
+		StartsAt: now.Add(-time.Hour),
+		EndsAt:   now.Add(24 * time.Hour),
+		Reason:   "vacation",
+	})
+	require.NoError(t, err)
+	assert.NotZero(t, current.AbsenceID)
+
+	_, err = storage.CreateAbsenceTx(ctx, nil, models.UserAbsence{
+		UserID:   "user3",
+		StartsAt: now.Add(48 * time.Hour),
+		EndsAt:   now.Add(72 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	absent, err := storage.GetAbsentUsersTx(ctx, nil, []string{"user1", "user2", "user3"}, now)
+	require.NoError(t, err)
+	assert.True(t, absent["user1"])
+	assert.False(t, absent["user3"])
+
+	absences, err := storage.GetAbsencesTx(ctx, nil, "user1")
+	require.NoError(t, err)
+	require.Len(t, absences, 1)
+	assert.Equal(t, "vacation", absences[0].Reason)
+
+	err = storage.DeleteAbsenceTx(ctx, nil, current.AbsenceID)
+	require.NoError(t, err)
+
+	absent, err = storage.GetAbsentUsersTx(ctx, nil, []string{"user1"}, now)
+	require.NoError(t, err)
+	assert.False(t, absent["user1"])
+
+	err = storage.DeleteAbsenceTx(ctx, nil, current.AbsenceID)
+	assert.Equal(t, models.ErrNotFound, err)
+}
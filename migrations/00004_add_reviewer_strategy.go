@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddReviewerStrategy, downAddReviewerStrategy)
+}
+
+func upAddReviewerStrategy(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE teams
+			ADD COLUMN IF NOT EXISTS reviewer_strategy TEXT NOT NULL DEFAULT 'least_loaded',
+			ADD COLUMN IF NOT EXISTS reviewer_seed BIGINT NOT NULL DEFAULT 0,
+			ADD COLUMN IF NOT EXISTS rr_cursor TEXT NOT NULL DEFAULT '';
+	`)
+	return err
+}
+
+func downAddReviewerStrategy(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE teams
+			DROP COLUMN IF EXISTS reviewer_strategy,
+			DROP COLUMN IF EXISTS reviewer_seed,
+			DROP COLUMN IF EXISTS rr_cursor;
+	`)
+	return err
+}
@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddReviewerCount, downAddReviewerCount)
+}
+
+func upAddReviewerCount(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE teams
+			ADD COLUMN IF NOT EXISTS reviewer_count INT NOT NULL DEFAULT 2 CHECK (reviewer_count >= 0);
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		ALTER TABLE pull_requests
+			ADD COLUMN IF NOT EXISTS target_reviewers INT NOT NULL DEFAULT 2 CHECK (target_reviewers >= 0);
+	`)
+	return err
+}
+
+func downAddReviewerCount(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE pull_requests DROP COLUMN IF EXISTS target_reviewers;
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		ALTER TABLE teams DROP COLUMN IF EXISTS reviewer_count;
+	`)
+	return err
+}
@@ -0,0 +1,72 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upCreateTeamFallbacks, downCreateTeamFallbacks)
+}
+
+func upCreateTeamFallbacks(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+	CREATE TABLE IF NOT EXISTS team_fallbacks (
+		team_name TEXT NOT NULL REFERENCES teams(name) ON DELETE CASCADE,
+		fallback_team TEXT NOT NULL REFERENCES teams(name) ON DELETE CASCADE,
+		position INT NOT NULL,
+		PRIMARY KEY (team_name, fallback_team),
+		CHECK (team_name <> fallback_team)
+	);
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		ALTER TABLE pull_requests
+			ADD COLUMN IF NOT EXISTS cross_team_reviewers TEXT[] NOT NULL DEFAULT '{}';
+	`)
+	if err != nil {
+		return err
+	}
+
+	return grantAppPrivileges(ctx, tx, "team_fallbacks")
+}
+
+func downCreateTeamFallbacks(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE pull_requests DROP COLUMN IF EXISTS cross_team_reviewers;
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		DROP TABLE IF EXISTS team_fallbacks;
+	`)
+	return err
+}
+
+// grantAppPrivileges выдает APP_USER права на таблицы, созданные после 00003
+func grantAppPrivileges(ctx context.Context, tx *sql.Tx, tables ...string) error {
+	username := os.Getenv("APP_USER")
+	if username == "" {
+		return fmt.Errorf("APP_USER is not set")
+	}
+	quotedUser := quotePostgresIdentifier(username)
+
+	for _, table := range tables {
+		_, err := tx.ExecContext(ctx, fmt.Sprintf(`
+			GRANT SELECT, INSERT, UPDATE, DELETE ON TABLE %s TO %s;
+		`, quotePostgresIdentifier(table), quotedUser))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
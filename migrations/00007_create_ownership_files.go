@@ -0,0 +1,36 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upCreateOwnershipFiles, downCreateOwnershipFiles)
+}
+
+func upCreateOwnershipFiles(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+	CREATE TABLE IF NOT EXISTS ownership_files (
+		scope TEXT NOT NULL CHECK (scope IN ('team', 'repository')),
+		scope_ref TEXT NOT NULL,
+		content TEXT NOT NULL,
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		PRIMARY KEY (scope, scope_ref)
+	);
+	`)
+	if err != nil {
+		return err
+	}
+
+	return grantAppPrivileges(ctx, tx, "ownership_files")
+}
+
+func downCreateOwnershipFiles(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		DROP TABLE IF EXISTS ownership_files;
+	`)
+	return err
+}
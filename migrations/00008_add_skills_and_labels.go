@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddSkillsAndLabels, downAddSkillsAndLabels)
+}
+
+func upAddSkillsAndLabels(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE users
+			ADD COLUMN IF NOT EXISTS skills TEXT[] NOT NULL DEFAULT '{}';
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		ALTER TABLE pull_requests
+			ADD COLUMN IF NOT EXISTS labels TEXT[] NOT NULL DEFAULT '{}';
+	`)
+	return err
+}
+
+func downAddSkillsAndLabels(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE pull_requests DROP COLUMN IF EXISTS labels;
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		ALTER TABLE users DROP COLUMN IF EXISTS skills;
+	`)
+	return err
+}
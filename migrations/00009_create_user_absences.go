@@ -0,0 +1,45 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upCreateUserAbsences, downCreateUserAbsences)
+}
+
+func upCreateUserAbsences(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+	CREATE TABLE IF NOT EXISTS user_absences (
+		absence_id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+		user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+		starts_at TIMESTAMPTZ NOT NULL,
+		ends_at TIMESTAMPTZ NOT NULL,
+		reason TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		CHECK (ends_at > starts_at)
+	);
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		CREATE INDEX IF NOT EXISTS idx_user_absences_user_period ON user_absences(user_id, starts_at, ends_at);
+	`)
+	if err != nil {
+		return err
+	}
+
+	return grantAppPrivileges(ctx, tx, "user_absences")
+}
+
+func downCreateUserAbsences(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		DROP TABLE IF EXISTS user_absences;
+	`)
+	return err
+}
@@ -0,0 +1,49 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upCreateAssignmentDecisions, downCreateAssignmentDecisions)
+}
+
+func upCreateAssignmentDecisions(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+	CREATE TABLE IF NOT EXISTS pr_assignment_decisions (
+		decision_id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+		pull_request_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+		reviewer_id TEXT NOT NULL,
+		action TEXT NOT NULL CHECK (action IN ('create', 'reassign', 'backfill')),
+		rule TEXT NOT NULL,
+		team_name TEXT NOT NULL,
+		strategy TEXT NOT NULL,
+		replaced_user_id TEXT NOT NULL DEFAULT '',
+		considered JSONB NOT NULL DEFAULT '[]',
+		excluded JSONB NOT NULL DEFAULT '[]',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	);
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		CREATE INDEX IF NOT EXISTS idx_pr_assignment_decisions_pr ON pr_assignment_decisions(pull_request_id, decision_id);
+	`)
+	if err != nil {
+		return err
+	}
+
+	return grantAppPrivileges(ctx, tx, "pr_assignment_decisions")
+}
+
+func downCreateAssignmentDecisions(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		DROP TABLE IF EXISTS pr_assignment_decisions;
+	`)
+	return err
+}
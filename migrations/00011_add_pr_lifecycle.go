@@ -0,0 +1,73 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddPRLifecycle, downAddPRLifecycle)
+}
+
+func upAddPRLifecycle(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE pull_requests DROP CONSTRAINT IF EXISTS pull_requests_status_check;
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		ALTER TABLE pull_requests ADD CONSTRAINT pull_requests_status_check
+			CHECK (status IN ('DRAFT', 'OPEN', 'CLOSED', 'REOPENED', 'MERGED'));
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		ALTER TABLE pull_requests ADD COLUMN IF NOT EXISTS closed_at TIMESTAMPTZ;
+	`)
+	return err
+}
+
+func downAddPRLifecycle(ctx context.Context, tx *sql.Tx) error {
+	// Новых статусов в старой схеме нет: черновики и переоткрытые становятся OPEN,
+	// закрытые без merge - MERGED
+	_, err := tx.ExecContext(ctx, `
+		UPDATE pull_requests SET status = 'OPEN' WHERE status IN ('DRAFT', 'REOPENED');
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE pull_requests SET status = 'MERGED', merged_at = COALESCE(merged_at, closed_at)
+		WHERE status = 'CLOSED';
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		ALTER TABLE pull_requests DROP CONSTRAINT IF EXISTS pull_requests_status_check;
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		ALTER TABLE pull_requests ADD CONSTRAINT pull_requests_status_check
+			CHECK (status IN ('OPEN', 'MERGED'));
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		ALTER TABLE pull_requests DROP COLUMN IF EXISTS closed_at;
+	`)
+	return err
+}
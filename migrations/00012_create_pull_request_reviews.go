@@ -0,0 +1,57 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upCreatePullRequestReviews, downCreatePullRequestReviews)
+}
+
+func upCreatePullRequestReviews(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+	CREATE TABLE IF NOT EXISTS pull_request_reviews (
+		pull_request_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+		reviewer_id TEXT NOT NULL,
+		state TEXT NOT NULL DEFAULT 'PENDING'
+			CHECK (state IN ('PENDING', 'APPROVED', 'CHANGES_REQUESTED', 'COMMENTED')),
+		body TEXT NOT NULL DEFAULT '',
+		requested_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		submitted_at TIMESTAMPTZ,
+		PRIMARY KEY (pull_request_id, reviewer_id)
+	);
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		CREATE INDEX IF NOT EXISTS idx_pull_request_reviews_reviewer ON pull_request_reviews(reviewer_id);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Уже назначенные ревьюеры ждут ревью с момента создания PR
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO pull_request_reviews (pull_request_id, reviewer_id, requested_at)
+		SELECT pr.pull_request_id, reviewer, pr.created_at
+		FROM pull_requests pr, unnest(pr.assigned_reviewers) AS reviewer
+		ON CONFLICT DO NOTHING;
+	`)
+	if err != nil {
+		return err
+	}
+
+	return grantAppPrivileges(ctx, tx, "pull_request_reviews")
+}
+
+func downCreatePullRequestReviews(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		DROP TABLE IF EXISTS pull_request_reviews;
+	`)
+	return err
+}
@@ -0,0 +1,68 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddMergePolicy, downAddMergePolicy)
+}
+
+func upAddMergePolicy(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE teams
+			ADD COLUMN IF NOT EXISTS min_approvals INT NOT NULL DEFAULT 0 CHECK (min_approvals >= 0),
+			ADD COLUMN IF NOT EXISTS block_on_changes_requested BOOLEAN NOT NULL DEFAULT true,
+			ADD COLUMN IF NOT EXISTS require_lead_approval BOOLEAN NOT NULL DEFAULT false,
+			ADD COLUMN IF NOT EXISTS lead_user_id TEXT REFERENCES users(user_id) ON DELETE SET NULL;
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+	CREATE TABLE IF NOT EXISTS pull_request_history (
+		history_id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+		pull_request_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+		event TEXT NOT NULL,
+		from_status TEXT NOT NULL DEFAULT '',
+		to_status TEXT NOT NULL DEFAULT '',
+		actor TEXT NOT NULL DEFAULT '',
+		details JSONB NOT NULL DEFAULT '{}',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	);
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		CREATE INDEX IF NOT EXISTS idx_pull_request_history_pr ON pull_request_history(pull_request_id, history_id);
+	`)
+	if err != nil {
+		return err
+	}
+
+	return grantAppPrivileges(ctx, tx, "pull_request_history")
+}
+
+func downAddMergePolicy(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		DROP TABLE IF EXISTS pull_request_history;
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		ALTER TABLE teams
+			DROP COLUMN IF EXISTS min_approvals,
+			DROP COLUMN IF EXISTS block_on_changes_requested,
+			DROP COLUMN IF EXISTS require_lead_approval,
+			DROP COLUMN IF EXISTS lead_user_id;
+	`)
+	return err
+}
@@ -0,0 +1,77 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upCreateCommentThreads, downCreateCommentThreads)
+}
+
+func upCreateCommentThreads(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+	CREATE TABLE IF NOT EXISTS pr_comment_threads (
+		thread_id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+		pull_request_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+		author_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+		file_path TEXT NOT NULL DEFAULT '',
+		line INT CHECK (line > 0),
+		resolved BOOLEAN NOT NULL DEFAULT false,
+		resolved_by TEXT NOT NULL DEFAULT '',
+		resolved_at TIMESTAMPTZ,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	);
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+	CREATE TABLE IF NOT EXISTS pr_comments (
+		comment_id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+		thread_id BIGINT NOT NULL REFERENCES pr_comment_threads(thread_id) ON DELETE CASCADE,
+		author_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+		body TEXT NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	);
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		CREATE INDEX IF NOT EXISTS idx_pr_comment_threads_pr ON pr_comment_threads(pull_request_id, thread_id);
+		CREATE INDEX IF NOT EXISTS idx_pr_comments_thread ON pr_comments(thread_id, comment_id);
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		ALTER TABLE teams
+			ADD COLUMN IF NOT EXISTS require_resolved_threads BOOLEAN NOT NULL DEFAULT false;
+	`)
+	if err != nil {
+		return err
+	}
+
+	return grantAppPrivileges(ctx, tx, "pr_comment_threads", "pr_comments")
+}
+
+func downCreateCommentThreads(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		DROP TABLE IF EXISTS pr_comments;
+		DROP TABLE IF EXISTS pr_comment_threads;
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		ALTER TABLE teams DROP COLUMN IF EXISTS require_resolved_threads;
+	`)
+	return err
+}
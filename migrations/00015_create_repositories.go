@@ -0,0 +1,64 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upCreateRepositories, downCreateRepositories)
+}
+
+func upCreateRepositories(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+	CREATE TABLE IF NOT EXISTS repositories (
+		name TEXT PRIMARY KEY,
+		default_team TEXT REFERENCES teams(name) ON DELETE SET NULL,
+		reviewer_strategy TEXT NOT NULL DEFAULT '',
+		reviewer_count INT CHECK (reviewer_count >= 0),
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Старые PR остаются без репозитория, для них repository и number - NULL
+	_, err = tx.ExecContext(ctx, `
+		ALTER TABLE pull_requests
+			ADD COLUMN IF NOT EXISTS repository TEXT REFERENCES repositories(name),
+			ADD COLUMN IF NOT EXISTS number INT CHECK (number > 0),
+			ADD CONSTRAINT pull_requests_repository_number_key UNIQUE (repository, number);
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		CREATE INDEX IF NOT EXISTS idx_pull_requests_repository ON pull_requests(repository);
+	`)
+	if err != nil {
+		return err
+	}
+
+	return grantAppPrivileges(ctx, tx, "repositories")
+}
+
+func downCreateRepositories(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE pull_requests
+			DROP CONSTRAINT IF EXISTS pull_requests_repository_number_key,
+			DROP COLUMN IF EXISTS repository,
+			DROP COLUMN IF EXISTS number;
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		DROP TABLE IF EXISTS repositories;
+	`)
+	return err
+}
@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddPRMetadataVersion, downAddPRMetadataVersion)
+}
+
+func upAddPRMetadataVersion(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE pull_requests
+			ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT '',
+			ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1 CHECK (version > 0);
+	`)
+	return err
+}
+
+func downAddPRMetadataVersion(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE pull_requests
+			DROP COLUMN IF EXISTS description,
+			DROP COLUMN IF EXISTS version;
+	`)
+	return err
+}
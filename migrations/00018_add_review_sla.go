@@ -0,0 +1,45 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddReviewSLA, downAddReviewSLA)
+}
+
+func upAddReviewSLA(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE teams
+			ADD COLUMN IF NOT EXISTS review_sla_hours INT NOT NULL DEFAULT 0 CHECK (review_sla_hours >= 0),
+			ADD COLUMN IF NOT EXISTS sla_reassign BOOLEAN NOT NULL DEFAULT false;
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		ALTER TABLE pull_request_reviews
+			ADD COLUMN IF NOT EXISTS overdue_at TIMESTAMPTZ;
+	`)
+	return err
+}
+
+func downAddReviewSLA(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE pull_request_reviews DROP COLUMN IF EXISTS overdue_at;
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		ALTER TABLE teams
+			DROP COLUMN IF EXISTS review_sla_hours,
+			DROP COLUMN IF EXISTS sla_reassign;
+	`)
+	return err
+}
@@ -0,0 +1,47 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddStalePRs, downAddStalePRs)
+}
+
+func upAddStalePRs(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE teams
+			ADD COLUMN IF NOT EXISTS stale_enabled BOOLEAN NOT NULL DEFAULT false,
+			ADD COLUMN IF NOT EXISTS stale_after_days INT NOT NULL DEFAULT 14 CHECK (stale_after_days > 0),
+			ADD COLUMN IF NOT EXISTS stale_close_after_days INT NOT NULL DEFAULT 7 CHECK (stale_close_after_days >= 0);
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		ALTER TABLE pull_requests
+			ADD COLUMN IF NOT EXISTS stale_at TIMESTAMPTZ;
+	`)
+	return err
+}
+
+func downAddStalePRs(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE pull_requests DROP COLUMN IF EXISTS stale_at;
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		ALTER TABLE teams
+			DROP COLUMN IF EXISTS stale_enabled,
+			DROP COLUMN IF EXISTS stale_after_days,
+			DROP COLUMN IF EXISTS stale_close_after_days;
+	`)
+	return err
+}
@@ -0,0 +1,29 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAllowUsersWithoutTeam, downAllowUsersWithoutTeam)
+}
+
+// Пользователь, удаленный из команды, остается в users (на него ссылаются PR и ревью),
+// но без команды
+func upAllowUsersWithoutTeam(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE users ALTER COLUMN team_name DROP NOT NULL;
+	`)
+	return err
+}
+
+// Не выполнится, пока есть пользователи без команды
+func downAllowUsersWithoutTeam(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE users ALTER COLUMN team_name SET NOT NULL;
+	`)
+	return err
+}
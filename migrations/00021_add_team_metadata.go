@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddTeamMetadata, downAddTeamMetadata)
+}
+
+// Лид команды уже хранится в teams.lead_user_id (00013)
+func upAddTeamMetadata(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE teams
+			ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT '',
+			ADD COLUMN IF NOT EXISTS chat_channel TEXT NOT NULL DEFAULT '',
+			ADD COLUMN IF NOT EXISTS review_policy TEXT NOT NULL DEFAULT '';
+	`)
+	return err
+}
+
+func downAddTeamMetadata(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE teams
+			DROP COLUMN IF EXISTS description,
+			DROP COLUMN IF EXISTS chat_channel,
+			DROP COLUMN IF EXISTS review_policy;
+	`)
+	return err
+}
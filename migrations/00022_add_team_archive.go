@@ -0,0 +1,27 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddTeamArchive, downAddTeamArchive)
+}
+
+func upAddTeamArchive(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE teams
+			ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
+	`)
+	return err
+}
+
+func downAddTeamArchive(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE teams DROP COLUMN IF EXISTS archived_at;
+	`)
+	return err
+}
@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddTeamParent, downAddTeamParent)
+}
+
+// Подкоманды: parent_name - родительская команда, parent_pool - добирать ревьюеров
+// из пула родительских команд, если своей команды не хватает
+func upAddTeamParent(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE teams
+			ADD COLUMN IF NOT EXISTS parent_name TEXT REFERENCES teams(name) ON DELETE SET NULL,
+			ADD COLUMN IF NOT EXISTS parent_pool BOOLEAN NOT NULL DEFAULT true;
+
+		CREATE INDEX IF NOT EXISTS idx_teams_parent ON teams(parent_name);
+	`)
+	return err
+}
+
+func downAddTeamParent(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		DROP INDEX IF EXISTS idx_teams_parent;
+		ALTER TABLE teams
+			DROP COLUMN IF EXISTS parent_name,
+			DROP COLUMN IF EXISTS parent_pool;
+	`)
+	return err
+}
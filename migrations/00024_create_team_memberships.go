@@ -0,0 +1,70 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upCreateTeamMemberships, downCreateTeamMemberships)
+}
+
+// Пользователь может состоять в нескольких командах; основная команда (is_primary)
+// необязательна и у пользователя не больше одной. Текущая команда из users.team_name
+// становится основной
+func upCreateTeamMemberships(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+	CREATE TABLE IF NOT EXISTS team_memberships (
+		user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+		team_name TEXT NOT NULL REFERENCES teams(name) ON DELETE CASCADE,
+		is_primary BOOLEAN NOT NULL DEFAULT false,
+		joined_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		PRIMARY KEY (user_id, team_name)
+	);
+
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_team_memberships_primary
+		ON team_memberships(user_id) WHERE is_primary;
+	CREATE INDEX IF NOT EXISTS idx_team_memberships_team ON team_memberships(team_name);
+
+	INSERT INTO team_memberships (user_id, team_name, is_primary)
+	SELECT user_id, team_name, true
+	FROM users
+	WHERE team_name IS NOT NULL
+	ON CONFLICT DO NOTHING;
+
+	ALTER TABLE users DROP COLUMN IF EXISTS team_name;
+
+	ALTER TABLE pull_requests
+		ADD COLUMN IF NOT EXISTS team_name TEXT REFERENCES teams(name) ON DELETE SET NULL;
+	`)
+	if err != nil {
+		return err
+	}
+
+	return grantAppPrivileges(ctx, tx, "team_memberships")
+}
+
+// Из нескольких команд пользователя остается основная, а если ее нет - первая по времени вступления
+func downCreateTeamMemberships(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE pull_requests DROP COLUMN IF EXISTS team_name;
+
+		ALTER TABLE users
+			ADD COLUMN IF NOT EXISTS team_name TEXT REFERENCES teams(name) ON DELETE CASCADE;
+
+		UPDATE users u
+		SET team_name = (
+			SELECT m.team_name FROM team_memberships m
+			WHERE m.user_id = u.user_id
+			ORDER BY m.is_primary DESC, m.joined_at, m.team_name
+			LIMIT 1
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_users_team ON users(team_name);
+
+		DROP TABLE IF EXISTS team_memberships;
+	`)
+	return err
+}
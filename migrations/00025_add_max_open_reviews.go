@@ -0,0 +1,29 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddMaxOpenReviews, downAddMaxOpenReviews)
+}
+
+// max_open_reviews - сколько открытых ревью может быть у ревьюера команды,
+// прежде чем он перестанет выбираться (0 - без ограничения)
+func upAddMaxOpenReviews(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE teams
+			ADD COLUMN IF NOT EXISTS max_open_reviews INT NOT NULL DEFAULT 0 CHECK (max_open_reviews >= 0);
+	`)
+	return err
+}
+
+func downAddMaxOpenReviews(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE teams DROP COLUMN IF EXISTS max_open_reviews;
+	`)
+	return err
+}